@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/config"
+	"github.com/mayknxyz/my-feeder/internal/deps"
+	"github.com/mayknxyz/my-feeder/internal/feed"
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// runImport handles `feeder import <kind> ...`.
+func runImport(args []string) error {
+	if len(args) == 0 || args[0] != "deps" {
+		return fmt.Errorf("usage: feeder import deps [-config path] [-dry-run] <go.mod|package.json|Cargo.toml>")
+	}
+	return runImportDeps(args[1:])
+}
+
+// runImportDeps subscribes to releases of every dependency in a
+// manifest. Dependencies that already have a github: or github-tags:
+// feed are skipped, so running it again after the manifest changes only
+// adds what's new.
+func runImportDeps(args []string) error {
+	fs := flag.NewFlagSet("import deps", flag.ContinueOnError)
+	configPath := fs.String("config", "", "config file (default "+config.DefaultConfigPath()+")")
+	dryRun := fs.Bool("dry-run", false, "print the feeds that would be added without writing the config")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: feeder import deps [-config path] [-dry-run] <go.mod|package.json|Cargo.toml>")
+	}
+	manifestPath := fs.Arg(0)
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	manifest, err := deps.ParseFile(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	// WHY: Dependencies resolve to repos on github.com, but with
+	// github_base_url set every github: feed is fetched from the
+	// Enterprise host, where those repos don't exist.
+	if cfg.Settings.GitHubBaseURL != "" {
+		return fmt.Errorf("import deps follows repos on github.com, but github_base_url sends github: feeds to %s", cfg.Settings.GitHubBaseURL)
+	}
+	gh := feed.NewGitHub(cfg.Settings.GitHubToken)
+
+	tag := "deps/" + manifest.Project
+
+	// WHY: Match on the repo regardless of feed type — a repo followed
+	// by tags shouldn't gain a second releases feed, and vice versa.
+	subscribed := make(map[string]bool)
+	for _, f := range cfg.Feeds {
+		switch f.Source() {
+		case model.SourceGitHub, model.SourceGitHubTags:
+			subscribed[strings.ToLower(f.Target())] = true
+		}
+	}
+
+	ctx := context.Background()
+	resolver := &deps.Resolver{
+		NPMRegistry: cfg.Settings.NPMRegistry,
+		CratesURL:   cfg.Settings.CratesURL,
+	}
+	inManifest := make(map[string]bool)
+	var added []model.Feed
+	failed := 0
+
+	fmt.Printf("Importing %d dependencies of %s\n", len(manifest.Deps), manifest.Project)
+	for _, dep := range manifest.Deps {
+		repo, err := resolver.Resolve(ctx, dep)
+		if errors.Is(err, deps.ErrNotGitHub) {
+			fmt.Printf("  ? %-40s not hosted on GitHub\n", dep.Name)
+			continue
+		}
+		if err != nil {
+			fmt.Printf("  ! %-40s %v\n", dep.Name, err)
+			failed++
+			continue
+		}
+
+		key := strings.ToLower(repo)
+		if inManifest[key] {
+			continue // another module from the same repo
+		}
+		inManifest[key] = true
+		if subscribed[key] {
+			fmt.Printf("  = %-40s already subscribed (%s)\n", dep.Name, repo)
+			continue
+		}
+
+		prefix := model.SourceGitHub
+		hasReleases, err := gh.HasReleases(ctx, repo)
+		if err != nil {
+			fmt.Printf("  ! %-40s %v\n", dep.Name, err)
+			failed++
+			continue
+		}
+		if !hasReleases {
+			prefix = model.SourceGitHubTags
+		}
+
+		f := model.Feed{Name: repo, URL: prefix + ":" + repo, Tag: tag}
+		added = append(added, f)
+		fmt.Printf("  + %-40s %s\n", dep.Name, f.URL)
+	}
+
+	// WHY: Only report stale subscriptions when every dependency
+	// resolved — otherwise a network error would make a current
+	// dependency look removed.
+	if failed == 0 {
+		for _, f := range cfg.Feeds {
+			if f.Tag == tag && !inManifest[strings.ToLower(f.Target())] {
+				fmt.Printf("  - %-40s no longer a dependency; remove it from the config if unwanted\n", f.Name)
+			}
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case len(added) == 0:
+		fmt.Println("Nothing to add.")
+	case *dryRun:
+		fmt.Printf("Would add %d feeds (dry run).\n", len(added))
+	default:
+		comment := fmt.Sprintf("Dependencies of %s, imported from %s on %s", manifest.Project, manifestPath, time.Now().Format(time.DateOnly))
+		if err := config.AppendFeeds(*configPath, comment, added); err != nil {
+			return err
+		}
+		fmt.Printf("Added %d feeds tagged %q.\n", len(added), tag)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d dependencies could not be resolved; run again to retry", failed)
+	}
+	return nil
+}
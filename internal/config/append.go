@@ -0,0 +1,48 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// AppendFeeds adds [[feeds]] entries to the end of the config file at
+// path, preceded by a comment line. The existing text is left untouched.
+func AppendFeeds(path, comment string, feeds []model.Feed) error {
+	if len(feeds) == 0 {
+		return nil
+	}
+	if path == "" {
+		path = DefaultConfigPath()
+	}
+
+	// WHY: Appending text rather than re-encoding the whole Config keeps
+	// the user's comments, ordering and formatting intact — the config
+	// file is hand-edited, and a round trip through the encoder would
+	// flatten it.
+	var buf bytes.Buffer
+	buf.WriteString("\n")
+	if comment != "" {
+		fmt.Fprintf(&buf, "# %s\n", comment)
+	}
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(struct {
+		Feeds []model.Feed `toml:"feeds"`
+	}{feeds}); err != nil {
+		return fmt.Errorf("encoding feeds: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("opening config file %s: %w", path, err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("writing config file %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -6,11 +6,16 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/adrg/xdg"
+	"github.com/andybalholm/cascadia"
+	"github.com/mayknxyz/my-feeder/internal/jsonpath"
 	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/release"
 )
 
 // defaultSettings provides sensible defaults for all settings.
@@ -18,6 +23,7 @@ import (
 var defaultSettings = Settings{
 	RefreshIntervalMinutes: 30,
 	RetentionDays:          7,
+	PodcastConcurrency:     2,
 }
 
 // Config is the top-level configuration loaded from config.toml.
@@ -33,7 +39,24 @@ type Settings struct {
 	BookmarkFile           string `toml:"bookmark_file"`
 	StateFile              string `toml:"state_file"`
 	CacheFile              string `toml:"cache_file"`
+	PodcastDir             string `toml:"podcast_dir"`
+	PodcastConcurrency     int    `toml:"podcast_concurrency"`
 	GitHubToken            string `toml:"github_token,omitempty"`
+	GitHubGraphQL          bool   `toml:"github_graphql,omitempty"`
+	GitHubBaseURL          string `toml:"github_base_url,omitempty"`
+	GitLabToken            string `toml:"gitlab_token,omitempty"`
+	GitLabBaseURL          string `toml:"gitlab_base_url,omitempty"`
+	GoProxy                string `toml:"goproxy,omitempty"`
+	NPMRegistry            string `toml:"npm_registry,omitempty"`
+	PyPIURL                string `toml:"pypi_url,omitempty"`
+	CratesURL              string `toml:"crates_url,omitempty"`
+	OSVURL                 string `toml:"osv_url,omitempty"`
+
+	// MastodonTokens maps an instance host to an access token.
+	MastodonTokens map[string]string `toml:"mastodon_tokens,omitempty"`
+
+	// GiteaTokens maps a Gitea or Forgejo host to an access token.
+	GiteaTokens map[string]string `toml:"gitea_tokens,omitempty"`
 }
 
 // DefaultConfigPath returns the default config file location following
@@ -45,6 +68,15 @@ func DefaultConfigPath() string {
 	return filepath.Join(xdg.ConfigHome, "feeder", "config.toml")
 }
 
+// GeminiKnownHostsPath returns where Gemini certificate pins are kept.
+//
+// WHY: Pins are trust decisions, not rebuildable data, so they belong in
+// the data dir rather than the cache. The path is fixed instead of
+// following state_file, which often points into a synced folder.
+func GeminiKnownHostsPath() string {
+	return filepath.Join(xdg.DataHome, "feeder", "gemini_known_hosts")
+}
+
 // Load reads and parses the config file at the given path.
 // If path is empty, it uses the default XDG config path.
 func Load(path string) (*Config, error) {
@@ -81,6 +113,7 @@ func (c *Config) validate() error {
 	if len(c.Feeds) == 0 {
 		return fmt.Errorf("no feeds configured — add at least one [[feeds]] entry")
 	}
+	seen := make(map[string]string, len(c.Feeds))
 	for i, f := range c.Feeds {
 		if f.Name == "" {
 			return fmt.Errorf("feed #%d: missing name", i+1)
@@ -88,6 +121,53 @@ func (c *Config) validate() error {
 		if f.URL == "" {
 			return fmt.Errorf("feed %q: missing url", f.Name)
 		}
+		if f.ID != "" && !validFeedID(f.ID) {
+			return fmt.Errorf("feed %q: id %q may only contain letters, digits, '-', '_' and '.', and can't be \".\" or \"..\"", f.Name, f.ID)
+		}
+		if _, err := release.NewFilter(f); err != nil {
+			return fmt.Errorf("feed %q: %w", f.Name, err)
+		}
+		if f.Mail != nil {
+			for _, pattern := range f.Mail.From {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("feed %q: mail from pattern %q: %w", f.Name, pattern, err)
+				}
+			}
+		}
+		for _, pattern := range f.Exclude {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("feed %q: exclude pattern %q: %w", f.Name, pattern, err)
+			}
+		}
+		if f.Exec != nil && f.Exec.Timeout != "" {
+			if d, err := time.ParseDuration(f.Exec.Timeout); err != nil || d <= 0 {
+				return fmt.Errorf("feed %q: exec timeout %q must be a positive duration like \"30s\"", f.Name, f.Exec.Timeout)
+			}
+		}
+		if f.Watch != nil && f.Watch.Selector != "" {
+			// LEARN: cascadia is the selector engine goquery uses, so a
+			// selector that parses here is one the fetcher can run.
+			if _, err := cascadia.ParseGroup(f.Watch.Selector); err != nil {
+				return fmt.Errorf("feed %q: watch selector %q: %w", f.Name, f.Watch.Selector, err)
+			}
+		}
+		if err := validateScrape(f); err != nil {
+			return fmt.Errorf("feed %q: %w", f.Name, err)
+		}
+		if err := validateJSON(f); err != nil {
+			return fmt.Errorf("feed %q: %w", f.Name, err)
+		}
+		if f.ICS != nil && f.ICS.LookaheadDays < 0 {
+			return fmt.Errorf("feed %q: lookahead_days must be >= 0", f.Name)
+		}
+		if f.MinPoints < 0 || f.MinComments < 0 {
+			return fmt.Errorf("feed %q: min_points and min_comments must be >= 0", f.Name)
+		}
+		id := feedID(f)
+		if other, ok := seen[id]; ok {
+			return fmt.Errorf("feed %q: id %q already used by feed %q", f.Name, id, other)
+		}
+		seen[id] = f.Name
 	}
 	if c.Settings.RefreshIntervalMinutes < 1 {
 		return fmt.Errorf("refresh_interval_minutes must be >= 1")
@@ -95,12 +175,78 @@ func (c *Config) validate() error {
 	if c.Settings.RetentionDays < 1 {
 		return fmt.Errorf("retention_days must be >= 1")
 	}
+	if c.Settings.PodcastConcurrency < 1 {
+		return fmt.Errorf("podcast_concurrency must be >= 1")
+	}
+	if c.Settings.GitHubGraphQL && c.Settings.GitHubToken == "" {
+		return fmt.Errorf("github_graphql requires github_token — the GraphQL API doesn't allow anonymous access")
+	}
+	return nil
+}
+
+// validateScrape checks a scrape: feed's selectors. Item and title are
+// required; the rest are optional.
+func validateScrape(f model.Feed) error {
+	if f.Source() != model.SourceScrape {
+		return nil
+	}
+	s := f.Scrape
+	if s == nil || s.Item == "" || s.Title == "" {
+		return fmt.Errorf("scrape feeds need a [feeds.scrape] block with item and title selectors")
+	}
+	selectors := []struct{ name, sel string }{
+		{"item", s.Item}, {"title", s.Title}, {"link", s.Link}, {"date", s.Date}, {"summary", s.Summary},
+	}
+	for _, x := range selectors {
+		if x.sel == "" {
+			continue
+		}
+		if _, err := cascadia.ParseGroup(x.sel); err != nil {
+			return fmt.Errorf("scrape %s selector %q: %w", x.name, x.sel, err)
+		}
+	}
+	return nil
+}
+
+// validateJSON checks a json: feed's paths. Items and title are
+// required; the other fields are optional.
+func validateJSON(f model.Feed) error {
+	if f.Source() != model.SourceJSON {
+		return nil
+	}
+	j := f.JSON
+	if j == nil || j.Items == "" || j.Title == "" {
+		return fmt.Errorf("json feeds need a [feeds.json] block with items and title paths")
+	}
+	paths := []struct{ name, path string }{
+		{"items", j.Items}, {"title", j.Title}, {"url", j.URL}, {"guid", j.GUID}, {"date", j.Date}, {"content", j.Content},
+	}
+	for _, p := range paths {
+		if p.path == "" {
+			continue
+		}
+		if _, err := jsonpath.Parse(p.path); err != nil {
+			return fmt.Errorf("json %s: %w", p.name, err)
+		}
+	}
 	return nil
 }
 
 // resolveDefaults fills in any settings that weren't specified in the
-// config file with XDG-compliant default paths.
+// config file with XDG-compliant default paths, and gives every feed
+// without an explicit id one derived from its URL.
 func (c *Config) resolveDefaults() {
+	for i := range c.Feeds {
+		c.Feeds[i].ID = feedID(c.Feeds[i])
+	}
+
+	if c.Settings.GoProxy == "" {
+		// WHY: Fall back to the same proxy the go command would use, so
+		// private proxies configured for builds work without repeating
+		// them here.
+		c.Settings.GoProxy = os.Getenv("GOPROXY")
+	}
+
 	if c.Settings.BookmarkFile == "" {
 		c.Settings.BookmarkFile = filepath.Join(xdg.DataHome, "feeder", "bookmarks.md")
 	} else {
@@ -120,6 +266,14 @@ func (c *Config) resolveDefaults() {
 	} else {
 		c.Settings.CacheFile = expandHome(c.Settings.CacheFile)
 	}
+
+	if c.Settings.PodcastDir == "" {
+		// LEARN: xdg.UserDirs reads ~/.config/user-dirs.dirs, so this
+		// follows a localised or relocated Music folder.
+		c.Settings.PodcastDir = filepath.Join(xdg.UserDirs.Music, "Podcasts")
+	} else {
+		c.Settings.PodcastDir = expandHome(c.Settings.PodcastDir)
+	}
 }
 
 // RetentionDays returns the effective retention for a feed, falling back
@@ -131,6 +285,33 @@ func (c *Config) RetentionDays(feed model.Feed) int {
 	return c.Settings.RetentionDays
 }
 
+// feedID returns the feed's configured id, or the URL-derived default.
+func feedID(f model.Feed) string {
+	if f.ID != "" {
+		return f.ID
+	}
+	return model.FeedIDFromURL(f.URL)
+}
+
+// validFeedID reports whether an explicit feed id is safe to use as a
+// cache key and file name.
+func validFeedID(id string) bool {
+	// WHY: "." and ".." pass the character check, but as a path element
+	// they name a directory rather than something of the feed's own.
+	if id == "." || id == ".." {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // expandHome replaces a leading "~/" with the user's home directory.
 func expandHome(path string) string {
 	if len(path) < 2 || path[:2] != "~/" {
@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
 )
 
 func TestLoad_ValidConfig(t *testing.T) {
@@ -88,6 +91,12 @@ url = "https://example.com/feed.xml"
 	if cfg.Settings.BookmarkFile == "" {
 		t.Error("bookmark file path should have a default")
 	}
+	if cfg.Settings.PodcastDir == "" {
+		t.Error("podcast directory should have a default")
+	}
+	if cfg.Settings.PodcastConcurrency != 2 {
+		t.Errorf("default podcast concurrency = %d, want 2", cfg.Settings.PodcastConcurrency)
+	}
 }
 
 func TestLoad_NoFeeds(t *testing.T) {
@@ -213,3 +222,318 @@ retention_days = 30
 		t.Errorf("custom feed retention = %d, want 30", days)
 	}
 }
+
+func TestLoad_FeedIDs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[[feeds]]
+name = "Pinned"
+id = "go-blog"
+url = "https://go.dev/blog/feed.atom"
+
+[[feeds]]
+name = "Derived"
+url = "https://example.com/feed.xml"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Feeds[0].ID != "go-blog" {
+		t.Errorf("feed[0].ID = %q, want %q", cfg.Feeds[0].ID, "go-blog")
+	}
+	if want := model.FeedIDFromURL("https://example.com/feed.xml"); cfg.Feeds[1].ID != want {
+		t.Errorf("feed[1].ID = %q, want derived %q", cfg.Feeds[1].ID, want)
+	}
+}
+
+func TestLoad_DuplicateFeedID(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[[feeds]]
+name = "One"
+id = "same"
+url = "https://example.com/one.xml"
+
+[[feeds]]
+name = "Two"
+id = "same"
+url = "https://example.com/two.xml"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("expected error for duplicate feed id")
+	}
+}
+
+func TestLoad_InvalidFeedID(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	for _, id := range []string{"../escape", ".", ".."} {
+		content := `
+[[feeds]]
+name = "Bad"
+id = "` + id + `"
+url = "https://example.com/feed.xml"
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Load(path); err == nil {
+			t.Errorf("expected error for feed id %q", id)
+		}
+	}
+}
+
+func TestLoad_GraphQLRequiresToken(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[settings]
+github_graphql = true
+
+[[feeds]]
+name = "Tokio"
+url = "github:tokio-rs/tokio"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("expected error for github_graphql without github_token")
+	}
+}
+
+func TestLoad_ReleaseFilters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[[feeds]]
+name = "Go"
+url = "github:golang/go"
+prereleases = false
+semver = ">=1.20, <2"
+only = "major|minor"
+tag_regex = "^go"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f := cfg.Feeds[0]
+	if f.Prereleases == nil || *f.Prereleases {
+		t.Errorf("prereleases = %v, want false", f.Prereleases)
+	}
+	if f.Semver != ">=1.20, <2" || f.Only != "major|minor" || f.TagRegex != "^go" {
+		t.Errorf("filter options = %q %q %q", f.Semver, f.Only, f.TagRegex)
+	}
+}
+
+func TestLoad_InvalidReleaseFilter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[[feeds]]
+name = "Go"
+url = "github:golang/go"
+semver = ">=one"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("expected error for invalid semver constraint")
+	}
+}
+
+func TestLoad_NegativeThreshold(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[[feeds]]
+name = "HN"
+url = "hn:top"
+min_points = -1
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("expected error for negative min_points")
+	}
+}
+
+func TestLoad_InvalidWatchSelector(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[[feeds]]
+name = "Pricing"
+url = "watch:https://example.com/pricing"
+
+[feeds.watch]
+selector = "div[unclosed"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("expected error for invalid CSS selector")
+	}
+}
+
+func TestLoad_ScrapeSelectors(t *testing.T) {
+	tests := []struct {
+		name, block, wantErr string
+	}{
+		{"valid", "item = \"article\"\ntitle = \"h2 a\"\ndate = \"time\"", ""},
+		{"missing title", "item = \"article\"", "item and title"},
+		{"bad summary", "item = \"article\"\ntitle = \"h2\"\nsummary = \"p[\"", "summary selector"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			content := "[[feeds]]\nname = \"Blog\"\nurl = \"scrape:https://example.com/\"\n\n[feeds.scrape]\n" + tt.block + "\n"
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_JSONPaths(t *testing.T) {
+	tests := []struct {
+		name, block, wantErr string
+	}{
+		{"valid", "items = \"$.data[*]\"\ntitle = \"name\"\ndate = \"meta['created.at']\"", ""},
+		{"missing items", "title = \"name\"", "items and title"},
+		{"bad url", "items = \"data\"\ntitle = \"name\"\nurl = \"links[x]\"", "json url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			content := "[[feeds]]\nname = \"Status\"\nurl = \"json:https://example.com/api\"\n\n[feeds.json]\n" + tt.block + "\n"
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidMailPattern(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+[[feeds]]
+name = "Newsletters"
+url = "mail:~/Mail/newsletters"
+
+[feeds.mail]
+from = ["[unclosed@example.com"]
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "mail from pattern") {
+		t.Errorf("err = %v, want a mail from pattern error", err)
+	}
+}
+
+func TestAppendFeeds(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	original := `# my feeds
+[[feeds]]
+name = "Go Blog"
+url = "https://go.dev/blog/feed.atom"
+`
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	added := []model.Feed{
+		{Name: "spf13/cobra", URL: "github:spf13/cobra", Tag: "deps/tool"},
+		{Name: "go-yaml/yaml", URL: "github-tags:go-yaml/yaml", Tag: "deps/tool"},
+	}
+	if err := AppendFeeds(path, "Dependencies of tool", added); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), original) {
+		t.Error("existing config text was modified")
+	}
+	if !strings.Contains(string(data), "# Dependencies of tool\n") {
+		t.Error("comment line missing")
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("reloading appended config: %v", err)
+	}
+	if len(cfg.Feeds) != 3 {
+		t.Fatalf("feeds count = %d, want 3", len(cfg.Feeds))
+	}
+	if got := cfg.Feeds[2]; got.URL != "github-tags:go-yaml/yaml" || got.Tag != "deps/tool" {
+		t.Errorf("feed[2] = %+v", got)
+	}
+}
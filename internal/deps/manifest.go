@@ -0,0 +1,224 @@
+// Package deps reads dependency manifests (go.mod, package.json,
+// Cargo.toml) and resolves each dependency to its source repository, so
+// release feeds can be derived from what a project actually uses.
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Ecosystems a dependency can belong to. The names match the ones OSV
+// uses, so the same values work for vulnerability queries.
+const (
+	Go    = "Go"
+	NPM   = "npm"
+	Cargo = "crates.io"
+)
+
+// Dependency is one package a manifest depends on.
+type Dependency struct {
+	Ecosystem string
+	Name      string
+	Version   string
+}
+
+// Manifest is a parsed dependency manifest.
+type Manifest struct {
+	// Project is the manifest's own name — the last element of a Go
+	// module path, or the package name for npm and Cargo.
+	Project string
+	Deps    []Dependency
+}
+
+// ParseOptions adjusts what a manifest parse keeps.
+type ParseOptions struct {
+	// Indirect keeps go.mod requirements marked "// indirect". They're
+	// noise when subscribing to releases, but a vulnerability in one
+	// still ships in the build.
+	Indirect bool
+}
+
+// ParseFile reads a manifest, choosing the parser by file name.
+func ParseFile(path string) (*Manifest, error) {
+	return ParseFileWith(path, ParseOptions{})
+}
+
+// ParseFileWith is ParseFile with options.
+func ParseFileWith(path string, opts ParseOptions) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest %s: %w", path, err)
+	}
+
+	var m *Manifest
+	switch name := filepath.Base(path); name {
+	case "go.mod":
+		m, err = parseGoMod(data, opts.Indirect)
+	case "package.json":
+		m, err = ParsePackageJSON(data)
+	case "Cargo.toml":
+		m, err = ParseCargoToml(data)
+	default:
+		return nil, fmt.Errorf("unsupported manifest %q (want go.mod, package.json or Cargo.toml)", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", path, err)
+	}
+
+	if m.Project == "" {
+		// Fall back to the directory the manifest lives in.
+		abs, _ := filepath.Abs(path)
+		m.Project = filepath.Base(filepath.Dir(abs))
+	}
+	return m, nil
+}
+
+// ParseGoMod extracts the direct requirements from a go.mod file.
+// Requirements marked "// indirect" are skipped — they're our
+// dependencies' dependencies, not ours.
+func ParseGoMod(data []byte) (*Manifest, error) {
+	return parseGoMod(data, false)
+}
+
+// parseGoMod extracts the requirements from a go.mod file, with or
+// without the indirect ones.
+func parseGoMod(data []byte, keepIndirect bool) (*Manifest, error) {
+	m := &Manifest{}
+	inRequire := false
+
+	// LEARN: bufio.Scanner splits input into lines by default, which is
+	// all the structure go.mod needs for the directives we care about.
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line, comment, _ := strings.Cut(sc.Text(), "//")
+		line = strings.TrimSpace(line)
+		indirect := strings.TrimSpace(comment) == "indirect"
+
+		switch {
+		case inRequire && line == ")":
+			inRequire = false
+		case inRequire:
+			if dep, ok := goRequire(line); ok && (keepIndirect || !indirect) {
+				m.Deps = append(m.Deps, dep)
+			}
+		case line == "require (":
+			inRequire = true
+		case strings.HasPrefix(line, "require "):
+			if dep, ok := goRequire(strings.TrimPrefix(line, "require ")); ok && (keepIndirect || !indirect) {
+				m.Deps = append(m.Deps, dep)
+			}
+		case strings.HasPrefix(line, "module "):
+			mod := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+			m.Project = mod[strings.LastIndex(mod, "/")+1:]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// goRequire parses a "path version" requirement line.
+func goRequire(line string) (Dependency, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return Dependency{}, false
+	}
+	return Dependency{Ecosystem: Go, Name: strings.Trim(fields[0], `"`), Version: fields[1]}, true
+}
+
+// ParsePackageJSON extracts dependencies and devDependencies from a
+// package.json file.
+func ParsePackageJSON(data []byte) (*Manifest, error) {
+	var pkg struct {
+		Name            string            `json:"name"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Project: pkg.Name}
+	m.Deps = append(m.Deps, sortedDeps(NPM, pkg.Dependencies)...)
+	m.Deps = append(m.Deps, sortedDeps(NPM, pkg.DevDependencies)...)
+	return m, nil
+}
+
+// ParseCargoToml extracts dependencies, dev-dependencies and
+// build-dependencies from a Cargo.toml file.
+func ParseCargoToml(data []byte) (*Manifest, error) {
+	var cargo struct {
+		Package struct {
+			Name string `toml:"name"`
+		} `toml:"package"`
+		Dependencies      map[string]any `toml:"dependencies"`
+		DevDependencies   map[string]any `toml:"dev-dependencies"`
+		BuildDependencies map[string]any `toml:"build-dependencies"`
+		Workspace         struct {
+			Dependencies map[string]any `toml:"dependencies"`
+		} `toml:"workspace"`
+	}
+	if err := toml.Unmarshal(data, &cargo); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Project: cargo.Package.Name}
+	for _, table := range []map[string]any{cargo.Dependencies, cargo.DevDependencies, cargo.BuildDependencies, cargo.Workspace.Dependencies} {
+		versions := make(map[string]string, len(table))
+		for name, spec := range table {
+			versions[crateName(name, spec)] = crateVersion(spec)
+		}
+		m.Deps = append(m.Deps, sortedDeps(Cargo, versions)...)
+	}
+	return m, nil
+}
+
+// crateName returns the crate a dependency entry refers to, honouring
+// `package = "..."` renames.
+func crateName(key string, spec any) string {
+	if t, ok := spec.(map[string]any); ok {
+		if pkg, ok := t["package"].(string); ok {
+			return pkg
+		}
+	}
+	return key
+}
+
+// crateVersion returns the version requirement of a dependency entry,
+// which is either a bare string or a table with a version key.
+func crateVersion(spec any) string {
+	switch v := spec.(type) {
+	case string:
+		return v
+	case map[string]any:
+		s, _ := v["version"].(string)
+		return s
+	}
+	return ""
+}
+
+// sortedDeps turns a name → version map into dependencies sorted by name,
+// so output and generated config are stable between runs.
+func sortedDeps(ecosystem string, versions map[string]string) []Dependency {
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]Dependency, 0, len(names))
+	for _, name := range names {
+		out = append(out, Dependency{Ecosystem: ecosystem, Name: name, Version: versions[name]})
+	}
+	return out
+}
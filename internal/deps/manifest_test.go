@@ -0,0 +1,114 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	data := []byte(`module github.com/example/tool
+
+go 1.22
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	golang.org/x/mod v0.17.0
+	gopkg.in/yaml.v3 v3.0.1 // a comment
+	github.com/davecgh/go-spew v1.1.1 // indirect
+)
+`)
+	m, err := ParseGoMod(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Project != "tool" {
+		t.Errorf("Project = %q, want %q", m.Project, "tool")
+	}
+	want := []Dependency{
+		{Go, "github.com/spf13/cobra", "v1.8.0"},
+		{Go, "golang.org/x/mod", "v0.17.0"},
+		{Go, "gopkg.in/yaml.v3", "v3.0.1"},
+	}
+	if !reflect.DeepEqual(m.Deps, want) {
+		t.Errorf("Deps = %+v, want %+v", m.Deps, want)
+	}
+}
+
+func TestParsePackageJSON(t *testing.T) {
+	data := []byte(`{
+  "name": "web",
+  "dependencies": {"react": "^18.2.0", "axios": "1.6.0"},
+  "devDependencies": {"vite": "^5.0.0"}
+}`)
+	m, err := ParsePackageJSON(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Project != "web" {
+		t.Errorf("Project = %q, want %q", m.Project, "web")
+	}
+	want := []Dependency{
+		{NPM, "axios", "1.6.0"},
+		{NPM, "react", "^18.2.0"},
+		{NPM, "vite", "^5.0.0"},
+	}
+	if !reflect.DeepEqual(m.Deps, want) {
+		t.Errorf("Deps = %+v, want %+v", m.Deps, want)
+	}
+}
+
+func TestParseCargoToml(t *testing.T) {
+	data := []byte(`[package]
+name = "cli"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+tokio = "1"
+json = { package = "serde_json", version = "1" }
+
+[dev-dependencies]
+insta = "1.34"
+`)
+	m, err := ParseCargoToml(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Project != "cli" {
+		t.Errorf("Project = %q, want %q", m.Project, "cli")
+	}
+	want := []Dependency{
+		{Cargo, "serde", "1.0"},
+		{Cargo, "serde_json", "1"},
+		{Cargo, "tokio", "1"},
+		{Cargo, "insta", "1.34"},
+	}
+	if !reflect.DeepEqual(m.Deps, want) {
+		t.Errorf("Deps = %+v, want %+v", m.Deps, want)
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "myapp")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "package.json")
+	if err := os.WriteFile(path, []byte(`{"dependencies": {"left-pad": "1.3.0"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Project != "myapp" {
+		t.Errorf("Project = %q, want directory name %q", m.Project, "myapp")
+	}
+
+	if _, err := ParseFile(filepath.Join(dir, "requirements.txt")); err == nil {
+		t.Error("expected error for unsupported manifest")
+	}
+}
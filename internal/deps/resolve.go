@@ -0,0 +1,183 @@
+package deps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"golang.org/x/net/html"
+)
+
+// Default registry endpoints used to look up source repositories. The
+// feed package reads package versions from the same registries.
+const (
+	DefaultNPMRegistry = "https://registry.npmjs.org"
+	DefaultCratesURL   = "https://crates.io"
+)
+
+// Resolver maps dependencies to the GitHub repositories they're
+// developed in.
+type Resolver struct {
+	Client      *http.Client
+	NPMRegistry string
+	CratesURL   string
+}
+
+// ErrNotGitHub is returned for dependencies whose source lives
+// somewhere other than GitHub.
+var ErrNotGitHub = errors.New("source repository is not on GitHub")
+
+// Resolve returns the "owner/repo" GitHub repository a dependency is
+// developed in.
+func (r *Resolver) Resolve(ctx context.Context, dep Dependency) (string, error) {
+	switch dep.Ecosystem {
+	case Go:
+		return r.resolveGo(ctx, dep.Name)
+	case NPM:
+		var pkg struct {
+			Repository json.RawMessage `json:"repository"`
+		}
+		u := httpx.OrDefault(r.NPMRegistry, DefaultNPMRegistry) + "/" + url.PathEscape(dep.Name)
+		if err := httpx.GetJSON(ctx, r.Client, u, nil, &pkg); err != nil {
+			return "", err
+		}
+		return githubRepo(npmRepositoryURL(pkg.Repository))
+	case Cargo:
+		var crate struct {
+			Crate struct {
+				Repository string `json:"repository"`
+			} `json:"crate"`
+		}
+		u := httpx.OrDefault(r.CratesURL, DefaultCratesURL) + "/api/v1/crates/" + url.PathEscape(dep.Name)
+		// WHY: crates.io rejects requests without a descriptive
+		// User-Agent, which GetJSON always sends.
+		if err := httpx.GetJSON(ctx, r.Client, u, nil, &crate); err != nil {
+			return "", err
+		}
+		return githubRepo(crate.Crate.Repository)
+	}
+	return "", fmt.Errorf("unsupported ecosystem %q", dep.Ecosystem)
+}
+
+// resolveGo resolves a Go module path. github.com paths map directly;
+// anything else is looked up through its go-import meta tag, the same
+// way the go command finds vanity import paths.
+func (r *Resolver) resolveGo(ctx context.Context, module string) (string, error) {
+	if strings.HasPrefix(module, "github.com/") {
+		return githubRepo("https://" + module)
+	}
+
+	u := "https://" + module + "?go-get=1"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", httpx.UserAgent)
+	resp, err := httpx.Client(r.Client).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching go-import for %s: %w", module, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching go-import for %s: %s", module, resp.Status)
+	}
+
+	repoURL, err := goImportRepo(resp.Body, module)
+	if err != nil {
+		return "", err
+	}
+	return githubRepo(repoURL)
+}
+
+// goImportRepo finds the repo root URL in the go-import meta tag whose
+// import prefix covers module.
+func goImportRepo(body io.Reader, module string) (string, error) {
+	// LEARN: html.Tokenizer walks the document token by token without
+	// building a tree — enough to pick <meta> tags out of a page.
+	z := html.NewTokenizer(body)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return "", fmt.Errorf("no go-import meta tag for %s", module)
+			}
+			return "", z.Err()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if tok.Data != "meta" {
+				continue
+			}
+			var name, content string
+			for _, a := range tok.Attr {
+				switch a.Key {
+				case "name":
+					name = a.Val
+				case "content":
+					content = a.Val
+				}
+			}
+			// content is "import-prefix vcs repo-root".
+			fields := strings.Fields(content)
+			if name != "go-import" || len(fields) != 3 {
+				continue
+			}
+			if module == fields[0] || strings.HasPrefix(module, fields[0]+"/") {
+				return fields[2], nil
+			}
+		}
+	}
+}
+
+// npmRepositoryURL extracts the URL from package.json's repository
+// field, which may be a string or an object with a url key.
+func npmRepositoryURL(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var obj struct {
+		URL string `json:"url"`
+	}
+	json.Unmarshal(raw, &obj)
+	return obj.URL
+}
+
+// githubRepo normalises a repository URL ("git+https://github.com/o/r.git",
+// "github:o/r", "https://github.com/o/r/tree/main/sub") to "o/r".
+func githubRepo(raw string) (string, error) {
+	s := strings.TrimSpace(raw)
+	if rest, ok := strings.CutPrefix(s, "github:"); ok {
+		s = "github.com/" + rest
+	}
+	s = strings.TrimPrefix(s, "git+")
+	if rest, ok := strings.CutPrefix(s, "git@github.com:"); ok {
+		s = "github.com/" + rest
+	}
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	s = strings.TrimPrefix(s, "git@")
+	s = strings.TrimPrefix(s, "www.")
+
+	rest, ok := strings.CutPrefix(s, "github.com/")
+	if !ok {
+		if strings.Count(raw, "/") == 1 && !strings.Contains(raw, ":") {
+			// npm's shorthand "owner/repo" means GitHub.
+			rest = raw
+		} else {
+			return "", ErrNotGitHub
+		}
+	}
+
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid GitHub repository URL %q", raw)
+	}
+	return parts[0] + "/" + strings.TrimSuffix(parts[1], ".git"), nil
+}
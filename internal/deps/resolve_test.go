@@ -0,0 +1,121 @@
+package deps
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGitHubRepo(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://github.com/owner/repo", "owner/repo"},
+		{"git+https://github.com/owner/repo.git", "owner/repo"},
+		{"git://github.com/owner/repo.git", "owner/repo"},
+		{"git@github.com:owner/repo.git", "owner/repo"},
+		{"github:owner/repo", "owner/repo"},
+		{"owner/repo", "owner/repo"},
+		{"https://github.com/owner/repo/tree/main/packages/sub", "owner/repo"},
+		{"https://www.github.com/owner/repo", "owner/repo"},
+	}
+	for _, tt := range tests {
+		got, err := githubRepo(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("githubRepo(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	if _, err := githubRepo("https://gitlab.com/owner/repo"); !errors.Is(err, ErrNotGitHub) {
+		t.Errorf("gitlab URL error = %v, want ErrNotGitHub", err)
+	}
+}
+
+func TestResolve_GoModules(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("go-get") != "1" {
+			t.Errorf("missing go-get=1 query: %s", r.URL)
+		}
+		host := r.Host
+		switch r.URL.Path {
+		case "/yaml", "/yaml/sub":
+			fmt.Fprintf(w, `<html><head>
+<meta name="go-source" content="%[1]s/yaml _ _">
+<meta name="go-import" content="%[1]s/yaml git https://github.com/go-yaml/yaml">
+</head></html>`, host)
+		case "/mod":
+			fmt.Fprintf(w, `<meta content="%s/mod git https://go.googlesource.com/mod" name="go-import">`, host)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	r := &Resolver{Client: srv.Client()}
+
+	tests := []struct {
+		module string
+		want   string
+	}{
+		{"github.com/spf13/cobra", "spf13/cobra"},
+		{"github.com/jackc/pgx/v5", "jackc/pgx"},
+		{host + "/yaml", "go-yaml/yaml"},
+		{host + "/yaml/sub", "go-yaml/yaml"},
+	}
+	for _, tt := range tests {
+		got, err := r.Resolve(t.Context(), Dependency{Ecosystem: Go, Name: tt.module})
+		if err != nil || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.module, got, err, tt.want)
+		}
+	}
+
+	if _, err := r.Resolve(t.Context(), Dependency{Ecosystem: Go, Name: host + "/mod"}); !errors.Is(err, ErrNotGitHub) {
+		t.Errorf("non-GitHub vanity import error = %v, want ErrNotGitHub", err)
+	}
+	if _, err := r.Resolve(t.Context(), Dependency{Ecosystem: Go, Name: host + "/missing"}); err == nil {
+		t.Error("expected error for missing go-import page")
+	}
+}
+
+func TestResolve_Registries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("registry request without User-Agent")
+		}
+		switch r.URL.EscapedPath() {
+		case "/npm/react":
+			fmt.Fprint(w, `{"repository":{"type":"git","url":"git+https://github.com/facebook/react.git","directory":"packages/react"}}`)
+		case "/npm/@scope%2Fpkg":
+			fmt.Fprint(w, `{"repository":"github:scope/pkg"}`)
+		case "/crates/api/v1/crates/serde":
+			fmt.Fprint(w, `{"crate":{"repository":"https://github.com/serde-rs/serde"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	r := &Resolver{NPMRegistry: srv.URL + "/npm", CratesURL: srv.URL + "/crates"}
+
+	tests := []struct {
+		dep  Dependency
+		want string
+	}{
+		{Dependency{Ecosystem: NPM, Name: "react"}, "facebook/react"},
+		{Dependency{Ecosystem: NPM, Name: "@scope/pkg"}, "scope/pkg"},
+		{Dependency{Ecosystem: Cargo, Name: "serde"}, "serde-rs/serde"},
+	}
+	for _, tt := range tests {
+		got, err := r.Resolve(t.Context(), tt.dep)
+		if err != nil || got != tt.want {
+			t.Errorf("Resolve(%s) = %q, %v, want %q", tt.dep.Name, got, err, tt.want)
+		}
+	}
+
+	if _, err := r.Resolve(t.Context(), Dependency{Ecosystem: NPM, Name: "missing"}); err == nil {
+		t.Error("expected error for unknown package")
+	}
+}
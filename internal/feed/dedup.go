@@ -34,17 +34,77 @@ func NormalizeTitle(title string) string {
 	return strings.Join(fields, " ")
 }
 
+// guidOnlySources are the sources whose GUIDs identify an item exactly,
+// so their articles are deduplicated by GUID alone. Distinct items from
+// them routinely share a title ("Update README", every occurrence of a
+// meetup, advisories for one package) or a URL (every change to a
+// watched page), and the looser tiers would swallow real news.
+var guidOnlySources = map[string]bool{
+	model.SourceGitHubSearch:  true,
+	model.SourceGitHubCommits: true,
+	model.SourceOSV:           true,
+	model.SourceMastodon:      true,
+	model.SourceWatch:         true,
+	model.SourceICS:           true,
+}
+
+// isDuplicateFrom is IsDuplicate for an article fetched from source,
+// which skips the URL and title tiers for guidOnlySources.
+func isDuplicateFrom(source string, article model.Article, existing []model.Article, retentionDays int) bool {
+	if guidOnlySources[source] {
+		return hasGUID(article, existing)
+	}
+	return IsDuplicate(article, existing, retentionDays)
+}
+
+// updatedSources are the sources whose items keep changing after they
+// were first reported, such as issues that get new comments. A fetched
+// article replaces the cached one with its GUID when it's newer, rather
+// than being dropped as a duplicate.
+var updatedSources = map[string]bool{
+	model.SourceGitHubSearch: true,
+}
+
+// updateCached overwrites the article in existing that has the fetched
+// article's GUID, if the fetched one was published later. It reports
+// whether existing had the GUID and whether the article was replaced.
+func updateCached(article model.Article, existing []model.Article) (found, updated bool) {
+	if article.GUID == "" {
+		return false, false
+	}
+	for i, e := range existing {
+		if e.GUID != article.GUID {
+			continue
+		}
+		if !article.PublishedAt.After(e.PublishedAt) {
+			return true, false
+		}
+		existing[i] = article
+		return true, true
+	}
+	return false, false
+}
+
+// hasGUID reports whether an existing article has the article's GUID.
+func hasGUID(article model.Article, existing []model.Article) bool {
+	if article.GUID == "" {
+		return false
+	}
+	for _, e := range existing {
+		if e.GUID == article.GUID {
+			return true
+		}
+	}
+	return false
+}
+
 // IsDuplicate checks whether an article is a duplicate of any existing
 // article using the 3-tier strategy: GUID → URL → fuzzy title.
 // retentionDays controls the window for fuzzy title matching.
 func IsDuplicate(article model.Article, existing []model.Article, retentionDays int) bool {
 	// Tier 1: exact GUID match.
-	if article.GUID != "" {
-		for _, e := range existing {
-			if e.GUID == article.GUID {
-				return true
-			}
-		}
+	if hasGUID(article, existing) {
+		return true
 	}
 
 	// Tier 2: exact URL match.
@@ -78,6 +138,13 @@ func IsDuplicate(article model.Article, existing []model.Article, retentionDays
 			continue
 		}
 
+		// WHY: Release titles differ by little more than the version
+		// ("tokio v1.40.0" vs "tokio v1.40.1") and score far above the
+		// threshold. Different versions are different releases.
+		if article.Version != "" && e.Version != "" && article.Version != e.Version {
+			continue
+		}
+
 		// WHY: Jaro-Winkler penalizes early-character mismatches more
 		// than late ones, which suits article titles where the meaningful
 		// words tend to come first ("Go 1.24 Released" vs "Go 1.24.0 Released").
@@ -139,3 +139,57 @@ func TestIsDuplicate_EmptyExisting(t *testing.T) {
 		t.Error("should not be duplicate against empty list")
 	}
 }
+
+func TestIsDuplicate_DifferentVersionsNotFuzzyMatched(t *testing.T) {
+	now := time.Now()
+	existing := []model.Article{
+		{GUID: "github:tokio-rs/tokio:1", Title: "tokio-rs/tokio v1.40.0", NormalizedTitle: "tokiorstokio v1400", Version: "v1.40.0", PublishedAt: now},
+	}
+	article := model.Article{GUID: "github:tokio-rs/tokio:2", Title: "tokio-rs/tokio v1.40.1", NormalizedTitle: "tokiorstokio v1401", Version: "v1.40.1", PublishedAt: now}
+
+	if IsDuplicate(article, existing, 7) {
+		t.Error("releases with different versions should not be fuzzy-matched")
+	}
+}
+
+func TestIsDuplicateFrom_GUIDOnlySources(t *testing.T) {
+	now := time.Now()
+	existing := []model.Article{
+		{GUID: "watch:https://example.com/status#aaaa", URL: "https://example.com/status", Title: "Status changed (+1 −1 lines)", NormalizedTitle: "status changed 1 1 lines", PublishedAt: now},
+	}
+	article := model.Article{GUID: "watch:https://example.com/status#bbbb", URL: "https://example.com/status", Title: "Status changed (+1 −1 lines)", NormalizedTitle: "status changed 1 1 lines", PublishedAt: now}
+
+	if isDuplicateFrom(model.SourceWatch, article, existing, 7) {
+		t.Error("a new GUID from a GUID-only source should not be a duplicate")
+	}
+	if !isDuplicateFrom(model.SourceWatch, existing[0], existing, 7) {
+		t.Error("the same GUID should still be a duplicate")
+	}
+	if !isDuplicateFrom(model.SourceRSS, article, existing, 7) {
+		t.Error("other sources should still match on URL")
+	}
+}
+
+func TestUpdateCached(t *testing.T) {
+	then := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	existing := []model.Article{
+		{GUID: "github-search:https://github.com/golang/go/issues/1", Title: "old title", PublishedAt: then},
+	}
+
+	found, updated := updateCached(model.Article{GUID: existing[0].GUID, Title: "same", PublishedAt: then}, existing)
+	if !found || updated {
+		t.Errorf("unchanged issue: found, updated = %v, %v, want true, false", found, updated)
+	}
+
+	found, updated = updateCached(model.Article{GUID: existing[0].GUID, Title: "new title", PublishedAt: then.Add(time.Hour)}, existing)
+	if !found || !updated {
+		t.Errorf("updated issue: found, updated = %v, %v, want true, true", found, updated)
+	}
+	if existing[0].Title != "new title" {
+		t.Errorf("cached Title = %q, want it refreshed in place", existing[0].Title)
+	}
+
+	if found, _ := updateCached(model.Article{GUID: "github-search:https://github.com/golang/go/issues/2", PublishedAt: then}, existing); found {
+		t.Error("a new issue should not be found in the cache")
+	}
+}
@@ -0,0 +1,104 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// writeScript creates an executable shell script and returns its path.
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "feed.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunExec_RSS(t *testing.T) {
+	script := writeScript(t, `cat <<'XML'
+<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Internal</title>
+<item><title>Deploy finished</title><link>https://ci.example/1</link><guid>ci-1</guid></item>
+</channel></rss>
+XML
+`)
+	articles, err := RunExec(t.Context(), model.Feed{Name: "CI", URL: "exec:" + script})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Title != "Deploy finished" || articles[0].GUID != "ci-1" {
+		t.Errorf("articles = %+v", articles)
+	}
+}
+
+func TestRunExec_JSONFeed(t *testing.T) {
+	script := writeScript(t, `echo '{"version":"https://jsonfeed.org/version/1.1","title":"x","items":[{"id":"j1","title":"From JSON Feed","url":"https://j.example/1"}]}'`)
+	articles, err := RunExec(t.Context(), model.Feed{Name: "JF", URL: "exec:" + script})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Title != "From JSON Feed" {
+		t.Errorf("articles = %+v", articles)
+	}
+}
+
+func TestRunExec_NDJSONWithArgsAndEnv(t *testing.T) {
+	script := writeScript(t, `echo "{\"title\":\"$1 for $TEAM\",\"url\":\"https://x.example/1\",\"published_at\":\"2024-05-01T10:00:00Z\"}"
+echo
+echo '{"title":"No URL"}'
+`)
+	feed := model.Feed{
+		Name: "Script",
+		URL:  "exec:" + script,
+		Exec: &model.ExecOptions{Args: []string{"hello world"}, Env: map[string]string{"TEAM": "infra"}},
+	}
+	articles, err := RunExec(t.Context(), feed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("got %d articles, want 2", len(articles))
+	}
+	first := articles[0]
+	if first.Title != "hello world for infra" || first.GUID != "https://x.example/1" {
+		t.Errorf("first = %q, GUID %q", first.Title, first.GUID)
+	}
+	if first.PublishedAt.Format("2006-01-02") != "2024-05-01" || first.NormalizedTitle == "" {
+		t.Errorf("first PublishedAt = %v, NormalizedTitle = %q", first.PublishedAt, first.NormalizedTitle)
+	}
+	if second := articles[1]; !strings.HasPrefix(second.GUID, "sha256:") || second.PublishedAt.IsZero() {
+		t.Errorf("second GUID = %q, PublishedAt = %v", second.GUID, second.PublishedAt)
+	}
+}
+
+func TestRunExec_FailureIncludesStderr(t *testing.T) {
+	script := writeScript(t, `echo "token expired" >&2; exit 3`)
+	_, err := RunExec(t.Context(), model.Feed{Name: "Broken", URL: "exec:" + script})
+	if err == nil {
+		t.Fatal("expected error for failing command")
+	}
+	if !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "token expired") {
+		t.Errorf("error = %q, want exit status and stderr", err)
+	}
+}
+
+func TestRunExec_Timeout(t *testing.T) {
+	script := writeScript(t, `echo "starting" >&2; exec sleep 10`)
+	feed := model.Feed{Name: "Slow", URL: "exec:" + script, Exec: &model.ExecOptions{Timeout: "100ms"}}
+	_, err := RunExec(t.Context(), feed)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("error = %v, want timeout", err)
+	}
+}
+
+func TestRunExec_BadOutput(t *testing.T) {
+	script := writeScript(t, `echo 'not json'`)
+	if _, err := RunExec(t.Context(), model.Feed{Name: "Bad", URL: "exec:" + script}); err == nil {
+		t.Error("expected error for unparseable output")
+	}
+}
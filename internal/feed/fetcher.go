@@ -8,6 +8,7 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/release"
 	"github.com/mayknxyz/my-feeder/internal/store"
 )
 
@@ -26,14 +27,45 @@ type FetchResult struct {
 type Fetcher struct {
 	Feeds       []model.Feed
 	Cache       *store.Cache
-	GitHubToken string
+	GitHub      *GitHub
+	GitLab      *GitLab
+	Gitea       *Gitea
+	GoProxy     *GoProxy
+	Registries  *Registries
+	OCI         *OCI
+	OSV         *OSV
+	Mastodon    *Mastodon
+	HackerNews  *HackerNews
+	Lobsters    *Lobsters
+	Watcher     *Watcher
+	Scraper     *Scraper
+	JSONAPI     *JSONAPI
+	Calendar    *Calendar
+	Gemini      *Gemini
 	RetentionFn func(model.Feed) int
+
+	// mu guards Cache while feeds are fetched concurrently.
+	mu sync.Mutex
+
+	// expanded is Feeds with groups replaced by their member feeds, as
+	// of the last refresh.
+	expanded []model.Feed
+
+	// batched holds GitHub releases prefetched via GraphQL, keyed by
+	// feed ID. It is filled before the fetch goroutines start and only
+	// read while they run.
+	batched map[string]BatchResult
 }
 
 // RefreshAll fetches all configured feeds concurrently, deduplicates
-// new articles against the cache, and returns results per feed.
+// new articles against the cache, and returns results per feed. Feed
+// groups are expanded first, so results hold one entry per member.
 func (f *Fetcher) RefreshAll(ctx context.Context) []FetchResult {
-	results := make([]FetchResult, len(f.Feeds))
+	feeds, failed := f.expandGroups(ctx)
+	f.expanded = feeds
+	f.batched = f.batchGitHub(ctx, feeds)
+
+	results := make([]FetchResult, len(feeds))
 
 	// LEARN: A buffered channel acts as a counting semaphore. Each
 	// goroutine sends a value before starting work and receives after
@@ -41,7 +73,7 @@ func (f *Fetcher) RefreshAll(ctx context.Context) []FetchResult {
 	sem := make(chan struct{}, maxConcurrent)
 	var wg sync.WaitGroup
 
-	for i, feed := range f.Feeds {
+	for i, feed := range feeds {
 		wg.Add(1)
 		go func(idx int, fd model.Feed) {
 			defer wg.Done()
@@ -55,34 +87,97 @@ func (f *Fetcher) RefreshAll(ctx context.Context) []FetchResult {
 	}
 
 	wg.Wait()
-	return results
+	return append(results, failed...)
+}
+
+// ActiveFeeds returns the feeds the last refresh fetched: the configured
+// feeds with groups expanded. Before the first refresh it returns the
+// configured feeds as they are.
+func (f *Fetcher) ActiveFeeds() []model.Feed {
+	if f.expanded != nil {
+		return f.expanded
+	}
+	return f.Feeds
+}
+
+// batchGitHub prefetches every GitHub release feed in batched GraphQL
+// queries when GraphQL mode is on. Returns nil otherwise, leaving each
+// feed to make its own REST call.
+func (f *Fetcher) batchGitHub(ctx context.Context, feeds []model.Feed) map[string]BatchResult {
+	if f.GitHub == nil || !f.GitHub.GraphQL {
+		return nil
+	}
+
+	var repos []string
+	ids := make(map[string][]string)
+	for _, fd := range feeds {
+		if !fd.IsGitHub() {
+			continue
+		}
+		repo := fd.GitHubRepo()
+		if _, ok := ids[repo]; !ok {
+			repos = append(repos, repo)
+		}
+		ids[repo] = append(ids[repo], fd.ID)
+	}
+	if len(repos) == 0 {
+		return nil
+	}
+
+	byRepo := f.GitHub.BatchReleases(ctx, repos)
+	byFeed := make(map[string]BatchResult, len(feeds))
+	for repo, res := range byRepo {
+		for _, id := range ids[repo] {
+			byFeed[id] = res
+		}
+	}
+	log.Info("GitHub releases batched", "repos", len(repos))
+	return byFeed
 }
 
 // fetchOne fetches a single feed, routes to the right parser, and
 // deduplicates against the existing cache.
 func (f *Fetcher) fetchOne(ctx context.Context, feed model.Feed) ([]model.Article, error) {
-	var raw []model.Article
-	var err error
+	f.mu.Lock()
+	cursor := f.Cache.Cursors[feed.ID]
+	f.mu.Unlock()
 
-	if feed.IsGitHub() {
-		raw, err = FetchGitHubReleases(ctx, feed.GitHubRepo(), f.GitHubToken)
-	} else {
-		raw, err = ParseRSS(feed.URL)
+	raw, cursor, err := f.fetchSource(ctx, feed, cursor)
+	if err != nil {
+		return nil, err
+	}
+	for i := range raw {
+		raw[i].FeedID = feed.ID
 	}
+
+	filter, err := release.NewFilter(feed)
 	if err != nil {
 		return nil, err
 	}
+	raw = filter.Apply(raw)
+
+	// WHY: The cache is shared by every fetch goroutine, so everything
+	// from reading existing articles to writing the merge is one
+	// critical section. The network work above stays outside it.
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
 	// Deduplicate against existing cached articles for this feed.
-	existing := f.Cache.ArticlesForFeed(feed.URL)
-	retDays := 7
-	if f.RetentionFn != nil {
-		retDays = f.RetentionFn(feed)
-	}
+	existing := f.Cache.ArticlesForFeed(feed.ID)
+	retDays := f.retentionDays(feed)
 
 	var fresh []model.Article
+	updated := 0
 	for _, a := range raw {
-		if !IsDuplicate(a, existing, retDays) {
+		if updatedSources[feed.Source()] {
+			if found, changed := updateCached(a, existing); found {
+				if changed {
+					updated++
+				}
+				continue
+			}
+		}
+		if !isDuplicateFrom(feed.Source(), a, existing, retDays) {
 			fresh = append(fresh, a)
 		}
 	}
@@ -91,40 +186,216 @@ func (f *Fetcher) fetchOne(ctx context.Context, feed model.Feed) ([]model.Articl
 		"feed", feed.Name,
 		"total", len(raw),
 		"new", len(fresh),
-		"dupes", len(raw)-len(fresh),
+		"updated", updated,
+		"dupes", len(raw)-len(fresh)-updated,
 	)
 
 	// Merge: new articles first, then existing (newest first).
 	merged := append(fresh, existing...)
-	f.Cache.SetArticles(feed.URL, merged)
-	f.Cache.LastFetched[feed.URL] = time.Now().UTC().Format(time.RFC3339)
+	f.Cache.SetArticles(feed.ID, merged)
+	f.Cache.LastFetched[feed.ID] = time.Now().UTC().Format(time.RFC3339)
+	f.Cache.Cursors[feed.ID] = cursor
 
 	return fresh, nil
 }
 
+// Preview fetches a single feed as a first refresh would and returns
+// what it extracted, after release filtering but without deduplicating
+// or touching the cache.
+func (f *Fetcher) Preview(ctx context.Context, feed model.Feed) ([]model.Article, error) {
+	raw, _, err := f.fetchSource(ctx, feed, store.Cursor{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range raw {
+		raw[i].FeedID = feed.ID
+	}
+	filter, err := release.NewFilter(feed)
+	if err != nil {
+		return nil, err
+	}
+	return filter.Apply(raw), nil
+}
+
+// fetchSource fetches raw articles from whichever source the feed's URL
+// points at, and returns the cursor to store for the next refresh.
+func (f *Fetcher) fetchSource(ctx context.Context, feed model.Feed, cursor store.Cursor) ([]model.Article, store.Cursor, error) {
+	if res, ok := f.batched[feed.ID]; ok {
+		if res.Err != nil {
+			return nil, cursor, res.Err
+		}
+		if articles, next, ok := res.Since(cursor); ok {
+			return articles, next, nil
+		}
+		// The batch didn't reach the cursor; page back over REST below.
+	}
+
+	switch feed.Source() {
+	case model.SourceGitHub:
+		if err := f.githubQuota(); err != nil {
+			return nil, cursor, err
+		}
+		return f.GitHub.Releases(ctx, feed.Target(), cursor)
+	case model.SourceGitHubTags:
+		if err := f.githubQuota(); err != nil {
+			return nil, cursor, err
+		}
+		return f.GitHub.Tags(ctx, feed.Target(), cursor)
+	case model.SourceGitHubCommits:
+		if err := f.githubQuota(); err != nil {
+			return nil, cursor, err
+		}
+		return f.GitHub.Commits(ctx, feed.Target(), cursor)
+	case model.SourceGitHubSearch:
+		raw, err := f.GitHub.SearchIssues(ctx, feed.Target())
+		return raw, cursor, err
+	case model.SourceGitHubStars:
+		return nil, cursor, fmt.Errorf("feed group %q can't be fetched directly", feed.Name)
+	case model.SourceGoMod:
+		return f.GoProxy.Versions(ctx, feed.Target(), cursor)
+	case model.SourceNPM:
+		raw, err := f.Registries.NPM(ctx, feed.Target())
+		return raw, cursor, err
+	case model.SourcePyPI:
+		raw, err := f.Registries.PyPI(ctx, feed.Target())
+		return raw, cursor, err
+	case model.SourceCrates:
+		raw, err := f.Registries.Crates(ctx, feed.Target())
+		return raw, cursor, err
+	case model.SourceOCI:
+		// WHY: Filtering before manifests are fetched saves three
+		// requests for every tag the feed would discard anyway.
+		filter, err := release.NewFilter(feed)
+		if err != nil {
+			return nil, cursor, err
+		}
+		return f.OCI.Tags(ctx, feed.Target(), cursor, filter)
+	case model.SourceOSV:
+		return f.OSV.Advisories(ctx, feed.Target(), cursor)
+	case model.SourceMastodon:
+		return f.Mastodon.Timeline(ctx, feed.Target(), cursor)
+	case model.SourceHN:
+		raw, err := f.HackerNews.Stories(ctx, feed.Target(), thresholds(feed))
+		return raw, cursor, err
+	case model.SourceLobsters:
+		raw, err := f.Lobsters.Stories(ctx, feed.Target(), thresholds(feed))
+		return raw, cursor, err
+	case model.SourceExec:
+		raw, err := RunExec(ctx, feed)
+		return raw, cursor, err
+	case model.SourceFile:
+		raw, err := ParseFile(feed.URL)
+		return raw, cursor, err
+	case model.SourceDir:
+		raw, err := ParseDir(feed.URL)
+		return raw, cursor, err
+	case model.SourceWatch:
+		return f.Watcher.Check(ctx, feed, cursor)
+	case model.SourceScrape:
+		raw, err := f.Scraper.Scrape(ctx, feed)
+		return raw, cursor, err
+	case model.SourceJSON:
+		raw, err := f.JSONAPI.Items(ctx, feed)
+		return raw, cursor, err
+	case model.SourceICS:
+		raw, err := f.Calendar.Events(ctx, feed)
+		return raw, cursor, err
+	case model.SourceMail:
+		raw, err := ParseMail(feed, time.Now().AddDate(0, 0, -f.retentionDays(feed)))
+		return raw, cursor, err
+	case model.SourceGemini:
+		return f.Gemini.Fetch(ctx, feed, cursor)
+	case model.SourceGitLab:
+		raw, err := f.GitLab.Releases(ctx, feed.Target())
+		return raw, cursor, err
+	case model.SourceGitea:
+		raw, err := f.Gitea.Releases(ctx, feed.Target())
+		return raw, cursor, err
+	default:
+		raw, err := ParseRSS(feed.URL)
+		return raw, cursor, err
+	}
+}
+
+// retentionDays returns how many days a feed's articles are kept.
+func (f *Fetcher) retentionDays(feed model.Feed) int {
+	if f.RetentionFn != nil {
+		return f.RetentionFn(feed)
+	}
+	return 7
+}
+
+// seenBefore returns a test for whether a version or tag was reported
+// by an earlier refresh, according to the cursor's Seen set.
+func seenBefore(cursor store.Cursor) func(name string) bool {
+	if cursor.Seen != nil {
+		return func(name string) bool {
+			_, ok := cursor.Seen[name]
+			return ok
+		}
+	}
+	// WHY: Cursors from before Seen was kept only name the newest
+	// version reported. Counting everything up to it as seen keeps the
+	// first refresh after an upgrade from repeating history.
+	latest, latestOK := release.Parse(cursor.LatestID)
+	return func(name string) bool {
+		if cursor.LatestID == "" {
+			return false
+		}
+		v, ok := release.Parse(name)
+		return !ok || !latestOK || release.Compare(v, latest) <= 0
+	}
+}
+
+// seenSet returns a cursor Seen set holding names.
+func seenSet(names []string) map[string]string {
+	seen := make(map[string]string, len(names))
+	for _, name := range names {
+		seen[name] = ""
+	}
+	return seen
+}
+
+// thresholds returns a feed's link-aggregator score thresholds.
+func thresholds(feed model.Feed) Thresholds {
+	return Thresholds{Points: feed.MinPoints, Comments: feed.MinComments}
+}
+
+// githubQuota returns ErrDeferred if the GitHub rate limit is too low
+// to spend on another feed this refresh.
+func (f *Fetcher) githubQuota() error {
+	if reset, low := f.GitHub.Exhausted(); low {
+		return fmt.Errorf("%w until %s", ErrDeferred, reset.Local().Format(time.Kitchen))
+	}
+	return nil
+}
+
 // ExpireOld removes articles older than their feed's retention period
 // from the cache. Bookmarked articles are never expired (handled by
 // the caller checking state before expiry).
 func (f *Fetcher) ExpireOld() int {
 	expired := 0
-	for _, feed := range f.Feeds {
-		retDays := 7
-		if f.RetentionFn != nil {
-			retDays = f.RetentionFn(feed)
-		}
-		cutoff := time.Now().AddDate(0, 0, -retDays)
+	for _, feed := range f.ActiveFeeds() {
+		cutoff := time.Now().AddDate(0, 0, -f.retentionDays(feed))
 
-		articles := f.Cache.ArticlesForFeed(feed.URL)
+		articles := f.Cache.ArticlesForFeed(feed.ID)
 		var kept []model.Article
 		for _, a := range articles {
-			if a.PublishedAt.Before(cutoff) {
+			// WHY: Events expire once they're over, not once they were
+			// announced — an article dated by a start months away must
+			// not count as old.
+			expiry := a.PublishedAt
+			if !a.EndsAt.IsZero() {
+				expiry = a.EndsAt
+			}
+			if expiry.Before(cutoff) {
 				expired++
 				continue
 			}
 			kept = append(kept, a)
 		}
 		if len(kept) != len(articles) {
-			f.Cache.SetArticles(feed.URL, kept)
+			f.Cache.SetArticles(feed.ID, kept)
 		}
 	}
 	if expired > 0 {
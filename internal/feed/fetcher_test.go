@@ -0,0 +1,45 @@
+package feed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+func TestExpireOld(t *testing.T) {
+	now := time.Now()
+	cache := store.NewCache()
+	cache.SetArticles("cal", []model.Article{
+		{GUID: "old-post", PublishedAt: now.AddDate(0, 0, -10)},
+		{GUID: "new-post", PublishedAt: now.AddDate(0, 0, -1)},
+		// Announced long ago, but only just over.
+		{GUID: "long-event", PublishedAt: now.AddDate(0, 0, -30), EndsAt: now.AddDate(0, 0, -2)},
+		{GUID: "ended-event", PublishedAt: now.AddDate(0, 0, -12), EndsAt: now.AddDate(0, 0, -9)},
+		{GUID: "future-event", PublishedAt: now.AddDate(0, 2, 0), EndsAt: now.AddDate(0, 2, 1)},
+	})
+
+	f := &Fetcher{
+		Feeds:       []model.Feed{{ID: "cal", Name: "Calendar"}},
+		Cache:       cache,
+		RetentionFn: func(model.Feed) int { return 7 },
+	}
+	if got := f.ExpireOld(); got != 2 {
+		t.Errorf("ExpireOld() = %d, want 2", got)
+	}
+
+	var kept []string
+	for _, a := range cache.ArticlesForFeed("cal") {
+		kept = append(kept, a.GUID)
+	}
+	want := []string{"new-post", "long-event", "future-event"}
+	if len(kept) != len(want) {
+		t.Fatalf("kept %v, want %v", kept, want)
+	}
+	for i := range want {
+		if kept[i] != want[i] {
+			t.Errorf("kept[%d] = %q, want %q", i, kept[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// DefaultGitLabURL is the GitLab instance used when none is configured.
+const DefaultGitLabURL = "https://gitlab.com"
+
+// GitLab fetches releases from a GitLab instance's REST API.
+type GitLab struct {
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+// gitlabRelease mirrors the fields we use from GitLab's releases API.
+type gitlabRelease struct {
+	Name        string    `json:"name"`
+	TagName     string    `json:"tag_name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	ReleasedAt  time.Time `json:"released_at"`
+	Upcoming    bool      `json:"upcoming_release"`
+	Author      struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	Links struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+// Releases fetches the latest releases of a project ("group/project",
+// subgroups allowed) and maps them to Articles.
+func (gl *GitLab) Releases(ctx context.Context, project string) ([]model.Article, error) {
+	project = strings.Trim(project, "/")
+	if !strings.Contains(project, "/") {
+		return nil, fmt.Errorf("invalid GitLab project %q, expected group/project", project)
+	}
+	base := strings.TrimSuffix(gl.BaseURL, "/")
+	if base == "" {
+		base = DefaultGitLabURL
+	}
+
+	// LEARN: GitLab addresses projects by ID or by URL-encoded path, so
+	// "group/sub/project" becomes "group%2Fsub%2Fproject".
+	api := fmt.Sprintf("%s/api/v4/projects/%s/releases?per_page=%d", base, url.PathEscape(project), releasesPerPage)
+
+	header := http.Header{}
+	if gl.Token != "" {
+		header.Set("PRIVATE-TOKEN", gl.Token)
+	}
+
+	var releases []gitlabRelease
+	if err := httpx.GetJSON(ctx, gl.Client, api, header, &releases); err != nil {
+		return nil, fmt.Errorf("fetching GitLab releases for %s: %w", project, err)
+	}
+
+	articles := make([]model.Article, 0, len(releases))
+	for _, rel := range releases {
+		// WHY: Upcoming releases have a future released_at — they're
+		// GitLab's equivalent of drafts.
+		if rel.Upcoming {
+			continue
+		}
+		link := rel.Links.Self
+		if link == "" {
+			link = fmt.Sprintf("%s/%s/-/releases/%s", base, project, url.PathEscape(rel.TagName))
+		}
+		articles = append(articles, releaseInfo{
+			guid:        fmt.Sprintf("gitlab:%s:%s", project, rel.TagName),
+			repo:        project,
+			name:        rel.Name,
+			tag:         rel.TagName,
+			url:         link,
+			author:      rel.Author.Username,
+			body:        rel.Description,
+			publishedAt: rel.ReleasedAt,
+			createdAt:   rel.CreatedAt,
+		}.article())
+	}
+	return articles, nil
+}
+
+// Gitea fetches releases from Gitea and Forgejo instances. The host is
+// part of each feed's target, so one Gitea serves every instance.
+type Gitea struct {
+	// Tokens maps an instance host to an access token. A token is only
+	// ever sent to its own host.
+	Tokens map[string]string
+	Client *http.Client
+}
+
+// giteaRelease mirrors the fields we use from Gitea's releases API,
+// which Forgejo shares.
+type giteaRelease struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	TagName     string    `json:"tag_name"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	CreatedAt   time.Time `json:"created_at"`
+	PublishedAt time.Time `json:"published_at"`
+	Author      struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// Releases fetches the latest releases for a "host/owner/repo" target
+// and maps them to Articles.
+func (gt *Gitea) Releases(ctx context.Context, target string) ([]model.Article, error) {
+	host, repo, ok := strings.Cut(strings.Trim(target, "/"), "/")
+	if !ok || host == "" {
+		return nil, fmt.Errorf("invalid Gitea target %q, expected host/owner/repo", target)
+	}
+	owner, name, err := splitRepo(repo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Gitea target %q: %w", target, err)
+	}
+
+	api := fmt.Sprintf("https://%s/api/v1/repos/%s/%s/releases?limit=%d", host, owner, name, releasesPerPage)
+	header := http.Header{}
+	// WHY: Targets can name any host, so a single token would be handed
+	// to every third-party instance a feed points at.
+	if token := gt.Tokens[host]; token != "" {
+		header.Set("Authorization", "token "+token)
+	}
+
+	var releases []giteaRelease
+	if err := httpx.GetJSON(ctx, gt.Client, api, header, &releases); err != nil {
+		return nil, fmt.Errorf("fetching Gitea releases for %s: %w", target, err)
+	}
+
+	articles := make([]model.Article, 0, len(releases))
+	for _, rel := range releases {
+		if rel.Draft {
+			continue
+		}
+		articles = append(articles, releaseInfo{
+			guid:        fmt.Sprintf("gitea:%s:%d", target, rel.ID),
+			repo:        repo,
+			name:        rel.Name,
+			tag:         rel.TagName,
+			url:         rel.HTMLURL,
+			author:      rel.Author.Login,
+			body:        rel.Body,
+			prerelease:  rel.Prerelease,
+			publishedAt: rel.PublishedAt,
+			createdAt:   rel.CreatedAt,
+		}.article())
+	}
+	return articles, nil
+}
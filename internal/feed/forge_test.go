@@ -0,0 +1,130 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGitLabReleases(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The project path arrives URL-encoded as a single segment.
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fsub%2Fproject/releases" {
+			t.Errorf("path = %q", r.URL.EscapedPath())
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat-test" {
+			t.Errorf("PRIVATE-TOKEN = %q", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		fmt.Fprint(w, `[
+			{"tag_name":"v2.0.0","upcoming_release":true,"released_at":"2099-01-01T00:00:00Z"},
+			{"name":"","tag_name":"v1.1.0","description":"Bug fixes.","released_at":"2025-02-01T00:00:00Z",
+			 "author":{"username":"maintainer"},"_links":{"self":"https://gitlab.example/group/sub/project/-/releases/v1.1.0"}},
+			{"name":"First","tag_name":"v1.0.0","created_at":"2025-01-01T00:00:00Z"}
+		]`)
+	}))
+	defer srv.Close()
+
+	gl := &GitLab{BaseURL: srv.URL, Token: "glpat-test"}
+	articles, err := gl.Releases(t.Context(), "group/sub/project")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("articles = %d, want 2 (upcoming skipped)", len(articles))
+	}
+
+	a := articles[0]
+	if a.Title != "group/sub/project v1.1.0" {
+		t.Errorf("Title = %q, want tag fallback", a.Title)
+	}
+	if a.GUID != "gitlab:group/sub/project:v1.1.0" || a.Version != "v1.1.0" {
+		t.Errorf("GUID/Version = %q / %q", a.GUID, a.Version)
+	}
+	if a.Author != "maintainer" || a.Content != "Bug fixes." {
+		t.Errorf("Author/Content = %q / %q", a.Author, a.Content)
+	}
+	if a.PublishedAt.Format("2006-01-02") != "2025-02-01" {
+		t.Errorf("PublishedAt = %v", a.PublishedAt)
+	}
+
+	// Without a self link, the URL is built from the base.
+	if want := srv.URL + "/group/sub/project/-/releases/v1.0.0"; articles[1].URL != want {
+		t.Errorf("URL = %q, want %q", articles[1].URL, want)
+	}
+	if articles[1].PublishedAt.Format("2006-01-02") != "2025-01-01" {
+		t.Errorf("PublishedAt should fall back to created_at, got %v", articles[1].PublishedAt)
+	}
+}
+
+func TestGitLabReleases_InvalidProject(t *testing.T) {
+	gl := &GitLab{}
+	if _, err := gl.Releases(t.Context(), "just-a-name"); err == nil {
+		t.Error("expected error for project without a group")
+	}
+}
+
+func TestGiteaReleases(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/forgejo/runner/releases" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "token gitea-test" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		fmt.Fprint(w, `[
+			{"id":3,"tag_name":"v4.0.0","draft":true},
+			{"id":2,"tag_name":"v3.5.0-rc1","name":"v3.5.0 RC 1","prerelease":true,"body":"Try it.",
+			 "html_url":"https://code.example/forgejo/runner/releases/tag/v3.5.0-rc1",
+			 "published_at":"2025-03-01T00:00:00Z","author":{"login":"releaser"}}
+		]`)
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "https://")
+	gt := &Gitea{Tokens: map[string]string{host: "gitea-test"}, Client: srv.Client()}
+	articles, err := gt.Releases(t.Context(), host+"/forgejo/runner")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 {
+		t.Fatalf("articles = %d, want 1 (draft skipped)", len(articles))
+	}
+
+	a := articles[0]
+	if a.Title != "v3.5.0 RC 1" || !a.Prerelease || a.Version != "v3.5.0-rc1" {
+		t.Errorf("Title/Prerelease/Version = %q / %v / %q", a.Title, a.Prerelease, a.Version)
+	}
+	if a.GUID != "gitea:"+host+"/forgejo/runner:2" {
+		t.Errorf("GUID = %q", a.GUID)
+	}
+	if a.Author != "releaser" {
+		t.Errorf("Author = %q", a.Author)
+	}
+}
+
+func TestGiteaReleases_TokenOnlyForItsHost(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("unlisted host got Authorization = %q", auth)
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "https://")
+	gt := &Gitea{Tokens: map[string]string{"git.example.com": "private"}, Client: srv.Client()}
+	if _, err := gt.Releases(t.Context(), host+"/forgejo/runner"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGiteaReleases_InvalidTarget(t *testing.T) {
+	gt := &Gitea{}
+	for _, target := range []string{"codeberg.org", "codeberg.org/only-owner"} {
+		if _, err := gt.Releases(t.Context(), target); err == nil {
+			t.Errorf("expected error for %q", target)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package feed
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html/charset"
+)
+
+// geminiPort is used for gemini:// URLs that don't name a port.
+const geminiPort = "1965"
+
+// maxGeminiRedirects bounds how many 3x responses are followed.
+const maxGeminiRedirects = 5
+
+// maxGeminiBody bounds how much of a response is read. Gemini has no
+// Content-Length, so a misbehaving server could otherwise stream forever.
+const maxGeminiBody = 4 << 20
+
+// maxGeminiEntries bounds how many posts a refresh fetches the body of.
+// A gemlog index lists every post ever written, and each body costs a
+// TLS handshake.
+const maxGeminiEntries = 20
+
+// Gemini fetches gemini:// feeds: gemfeed-style gemtext index pages and
+// Atom or RSS documents served over Gemini.
+type Gemini struct {
+	// KnownHostsFile is where certificate pins are kept. Empty keeps
+	// them in memory only.
+	KnownHostsFile string
+
+	// mu guards hosts, which concurrent fetches share.
+	mu    sync.Mutex
+	hosts map[string]knownHost
+}
+
+// knownHost is a pinned server certificate.
+type knownHost struct {
+	fingerprint string
+	notAfter    time.Time
+}
+
+// geminiResponse is a successful (2x) response, body decoded to UTF-8.
+type geminiResponse struct {
+	url       *url.URL
+	mediaType string
+	body      string
+}
+
+// Fetch requests a gemini: feed's index and returns its posts newer than
+// the cursor, with each post's gemtext converted to Markdown.
+func (g *Gemini) Fetch(ctx context.Context, feed model.Feed, cursor store.Cursor) ([]model.Article, store.Cursor, error) {
+	resp, err := g.get(ctx, feed.URL)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	var articles []model.Article
+	switch resp.mediaType {
+	case "text/gemini":
+		articles = gemfeedArticles(resp.url, resp.body)
+	case "application/atom+xml", "application/rss+xml", "application/xml", "text/xml":
+		parsed, err := gofeed.NewParser().ParseString(resp.body)
+		if err != nil {
+			return nil, cursor, fmt.Errorf("parsing feed %s: %w", resp.url, err)
+		}
+		articles = mapItems(resp.url.String(), parsed)
+	default:
+		return nil, cursor, fmt.Errorf("%s: unsupported content type %q", resp.url, resp.mediaType)
+	}
+
+	// WHY: Gemfeed dates have no time of day, so posts from the newest
+	// day are fetched again next refresh. Dedup drops them by GUID.
+	next := cursor
+	var fresh []model.Article
+	for _, a := range articles {
+		if a.PublishedAt.Before(cursor.Since) {
+			continue
+		}
+		fresh = append(fresh, a)
+		if a.PublishedAt.After(next.Since) {
+			next.Since = a.PublishedAt
+		}
+	}
+	slices.SortStableFunc(fresh, func(a, b model.Article) int { return b.PublishedAt.Compare(a.PublishedAt) })
+	if len(fresh) > maxGeminiEntries {
+		fresh = fresh[:maxGeminiEntries]
+	}
+
+	failed := 0
+	for i, a := range fresh {
+		if a.Content != "" || !strings.HasPrefix(a.URL, "gemini://") {
+			continue
+		}
+		content, err := g.post(ctx, a.URL)
+		if err != nil {
+			failed++
+			continue
+		}
+		fresh[i].Content = content
+		if a.Summary == "" {
+			fresh[i].Summary = truncate(firstParagraph(content), 200)
+		}
+	}
+	if failed > 0 {
+		log.Warn("Some gemini posts could not be fetched", "feed", feed.Name, "count", failed)
+	}
+	return fresh, next, nil
+}
+
+// post fetches one post and returns it as Markdown.
+func (g *Gemini) post(ctx context.Context, rawURL string) (string, error) {
+	resp, err := g.get(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	switch resp.mediaType {
+	case "text/gemini":
+		return gemtextMarkdown(resp.body, resp.url), nil
+	case "text/plain", "text/markdown":
+		return strings.TrimSpace(resp.body), nil
+	}
+	return "", fmt.Errorf("%s: unsupported content type %q", resp.url, resp.mediaType)
+}
+
+// get requests rawURL, following redirects, and returns the first
+// successful response. Every other status is an error.
+func (g *Gemini) get(ctx context.Context, rawURL string) (*geminiResponse, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing gemini URL %q: %w", rawURL, err)
+	}
+
+	for range maxGeminiRedirects + 1 {
+		if u.Scheme != "gemini" || u.Host == "" {
+			return nil, fmt.Errorf("%s is not a gemini:// URL", u)
+		}
+		status, meta, body, err := g.request(ctx, u)
+		if err != nil {
+			return nil, fmt.Errorf("requesting %s: %w", u, err)
+		}
+
+		switch status / 10 {
+		case 2:
+			return decodeGeminiBody(u, meta, body)
+		case 3:
+			next, err := u.Parse(meta)
+			if err != nil {
+				return nil, fmt.Errorf("%s redirects to bad URL %q: %w", u, meta, err)
+			}
+			u = next
+		case 1:
+			return nil, fmt.Errorf("%s asks for input (%d %s)", u, status, meta)
+		case 6:
+			return nil, fmt.Errorf("%s requires a client certificate (%d %s)", u, status, meta)
+		default:
+			return nil, fmt.Errorf("%s: %d %s", u, status, meta)
+		}
+	}
+	return nil, fmt.Errorf("%s: more than %d redirects", rawURL, maxGeminiRedirects)
+}
+
+// decodeGeminiBody reads a 2x response's media type and converts text
+// bodies in other charsets to UTF-8.
+func decodeGeminiBody(u *url.URL, meta string, body []byte) (*geminiResponse, error) {
+	// The spec's default when a server sends no media type.
+	if strings.TrimSpace(meta) == "" {
+		meta = "text/gemini; charset=utf-8"
+	}
+	mediaType, params, err := mime.ParseMediaType(meta)
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad media type %q: %w", u, meta, err)
+	}
+	if cs := params["charset"]; cs != "" && !strings.EqualFold(cs, "utf-8") && !strings.EqualFold(cs, "us-ascii") {
+		r, err := charset.NewReaderLabel(cs, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("%s: charset %q: %w", u, cs, err)
+		}
+		if body, err = io.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("%s: decoding %s: %w", u, cs, err)
+		}
+	}
+	return &geminiResponse{url: u, mediaType: mediaType, body: string(body)}, nil
+}
+
+// request makes one Gemini request and returns the response header's
+// status and meta, and the body.
+func (g *Gemini) request(ctx context.Context, u *url.URL) (status int, meta string, body []byte, err error) {
+	port := u.Port()
+	if port == "" {
+		port = geminiPort
+	}
+	addr := net.JoinHostPort(u.Hostname(), port)
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: httpx.Timeout},
+		Config: &tls.Config{
+			ServerName: u.Hostname(),
+			MinVersion: tls.VersionTLS12,
+			// WHY: Most capsules use self-signed certificates, so CA
+			// verification is off and trust comes from pinning the
+			// certificate first seen for each host instead.
+			InsecureSkipVerify: true,
+			VerifyConnection: func(cs tls.ConnectionState) error {
+				if len(cs.PeerCertificates) == 0 {
+					return errors.New("server sent no certificate")
+				}
+				return g.verify(addr, cs.PeerCertificates[0])
+			},
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	defer conn.Close()
+
+	// LEARN: context.AfterFunc runs its function when ctx is cancelled,
+	// here unblocking a read stuck on a slow server.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	if err := conn.SetDeadline(time.Now().Add(httpx.Timeout)); err != nil {
+		return 0, "", nil, err
+	}
+
+	if _, err := io.WriteString(conn, u.String()+"\r\n"); err != nil {
+		return 0, "", nil, err
+	}
+
+	// The header is "<status> <meta>\r\n", with meta at most 1024 bytes.
+	r := bufio.NewReaderSize(conn, 2048)
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return 0, "", nil, fmt.Errorf("reading response header: %w", err)
+	}
+	header := strings.TrimRight(string(line), "\r\n")
+	code, meta, _ := strings.Cut(header, " ")
+	status, err = strconv.Atoi(code)
+	if err != nil || len(code) != 2 {
+		return 0, "", nil, fmt.Errorf("malformed response header %q", header)
+	}
+	if status/10 != 2 {
+		return status, strings.TrimSpace(meta), nil, nil
+	}
+
+	body, err = io.ReadAll(io.LimitReader(r, maxGeminiBody+1))
+	// WHY: Many servers close the connection without a TLS close_notify,
+	// which Go reports as an unexpected EOF after the whole body arrived.
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, "", nil, fmt.Errorf("reading response body: %w", err)
+	}
+	if len(body) > maxGeminiBody {
+		return 0, "", nil, fmt.Errorf("response body exceeds %d bytes", maxGeminiBody)
+	}
+	return status, strings.TrimSpace(meta), body, nil
+}
+
+// verify checks cert against the one pinned for addr, pinning it if the
+// host is new. A different certificate is an error until the pinned one
+// has expired, when the new one replaces it.
+func (g *Gemini) verify(addr string, cert *x509.Certificate) error {
+	sum := sha256.Sum256(cert.Raw)
+	fingerprint := "sha256:" + hex.EncodeToString(sum[:])
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.loadHosts(); err != nil {
+		return err
+	}
+
+	known, ok := g.hosts[addr]
+	switch {
+	case ok && known.fingerprint == fingerprint:
+		return nil
+	case ok && time.Now().Before(known.notAfter):
+		return fmt.Errorf("certificate for %s changed (got %s, pinned %s); if the capsule replaced it, delete its line from %s",
+			addr, fingerprint, known.fingerprint, g.KnownHostsFile)
+	case ok:
+		log.Info("Gemini certificate replaced after expiry", "host", addr)
+	}
+
+	g.hosts[addr] = knownHost{fingerprint: fingerprint, notAfter: cert.NotAfter.UTC()}
+	return g.saveHosts()
+}
+
+// loadHosts reads KnownHostsFile on first use. Each line holds an
+// address, a fingerprint and the pinned certificate's expiry.
+func (g *Gemini) loadHosts() error {
+	if g.hosts != nil {
+		return nil
+	}
+	g.hosts = make(map[string]knownHost)
+	if g.KnownHostsFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(g.KnownHostsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading gemini known hosts: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		notAfter, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			continue
+		}
+		g.hosts[fields[0]] = knownHost{fingerprint: fields[1], notAfter: notAfter}
+	}
+	return nil
+}
+
+// saveHosts writes the pins back to KnownHostsFile.
+func (g *Gemini) saveHosts() error {
+	if g.KnownHostsFile == "" {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString("# Gemini certificates pinned on first use. Delete a line to trust a host's new certificate.\n")
+	addrs := make([]string, 0, len(g.hosts))
+	for addr := range g.hosts {
+		addrs = append(addrs, addr)
+	}
+	slices.Sort(addrs)
+	for _, addr := range addrs {
+		h := g.hosts[addr]
+		fmt.Fprintf(&b, "%s %s %s\n", addr, h.fingerprint, h.notAfter.Format(time.RFC3339))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(g.KnownHostsFile), 0o755); err != nil {
+		return fmt.Errorf("creating gemini known hosts directory: %w", err)
+	}
+	tmp := g.KnownHostsFile + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("writing gemini known hosts: %w", err)
+	}
+	if err := os.Rename(tmp, g.KnownHostsFile); err != nil {
+		return fmt.Errorf("writing gemini known hosts: %w", err)
+	}
+	return nil
+}
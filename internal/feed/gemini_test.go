@@ -0,0 +1,311 @@
+package feed
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+const testGemlog = "# Example gemlog\r\n" +
+	"\r\n" +
+	"=> /about.gmi About me\r\n" +
+	"=> /gemlog/2024-03-14-tofu.gmi 2024-03-14 - Trust on first use\r\n" +
+	"=> 2024-02-01-hello.gmi 2024-02-01 Hello, Geminispace\r\n" +
+	"=> gemini://127.0.0.1:1/post.gmi 2023-12-24 A cross-post\r\n"
+
+const testPost = "# Trust on first use\n" +
+	"\n" +
+	"Gemini servers mostly sign their own certificates.\n" +
+	"Clients pin them instead.\n" +
+	"=> https://en.wikipedia.org/wiki/Trust_on_first_use Wikipedia\n" +
+	"* one\n" +
+	"* two\n" +
+	"```shell\n" +
+	"openssl x509 -fingerprint\n" +
+	"```\n"
+
+const testGeminiAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example gemlog</title>
+  <entry>
+    <id>gemini://HOST/gemlog/2024-03-14-tofu.gmi</id>
+    <title>Trust on first use</title>
+    <link href="gemini://HOST/gemlog/2024-03-14-tofu.gmi"/>
+    <updated>2024-03-14T09:00:00Z</updated>
+  </entry>
+</feed>`
+
+// newGeminiServer starts a Gemini server on localhost with a fresh
+// self-signed certificate. routes maps a request path to the response
+// sent back, header included; "HOST" in a response is replaced with the
+// server's address.
+func newGeminiServer(t *testing.T, routes map[string]string) (addr string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	addr = ln.Addr().String()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				u, err := url.Parse(strings.TrimSpace(line))
+				if err != nil {
+					return
+				}
+				resp, ok := routes[u.Path]
+				if !ok {
+					resp = "51 Not found\r\n"
+				}
+				conn.Write([]byte(strings.ReplaceAll(resp, "HOST", addr)))
+			}()
+		}
+	}()
+	return addr, cert
+}
+
+func TestGeminiFetch_Gemfeed(t *testing.T) {
+	addr, _ := newGeminiServer(t, map[string]string{
+		"/gemlog/":                     "20 text/gemini\r\n" + testGemlog,
+		"/gemlog/2024-03-14-tofu.gmi":  "20 text/gemini; lang=en\r\n" + testPost,
+		"/gemlog/2024-02-01-hello.gmi": "20 text/plain; charset=iso-8859-1\r\nCaf\xe9 opening.\r\n",
+		"/old/gemlog/":                 "31 /gemlog/\r\n",
+	})
+	hosts := filepath.Join(t.TempDir(), "gemini_known_hosts")
+	g := &Gemini{KnownHostsFile: hosts}
+
+	feed := model.Feed{Name: "Example", URL: "gemini://" + addr + "/old/gemlog/"}
+	articles, cursor, err := g.Fetch(context.Background(), feed, store.Cursor{})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("got %d articles, want 3", len(articles))
+	}
+
+	first := articles[0]
+	if first.Title != "Trust on first use" {
+		t.Errorf("Title = %q, want %q", first.Title, "Trust on first use")
+	}
+	wantURL := "gemini://" + addr + "/gemlog/2024-03-14-tofu.gmi"
+	if first.URL != wantURL || first.GUID != wantURL {
+		t.Errorf("URL, GUID = %q, %q, want %q", first.URL, first.GUID, wantURL)
+	}
+	if want := time.Date(2024, 3, 14, 0, 0, 0, 0, time.Local); !first.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", first.PublishedAt, want)
+	}
+	if !strings.Contains(first.Content, "[Wikipedia](https://en.wikipedia.org/wiki/Trust_on_first_use)") {
+		t.Errorf("Content missing link:\n%s", first.Content)
+	}
+	if first.Summary != "Gemini servers mostly sign their own certificates." {
+		t.Errorf("Summary = %q", first.Summary)
+	}
+
+	// The relative link resolves against the redirect target, and the
+	// Latin-1 plain-text body is decoded.
+	if articles[1].Title != "Hello, Geminispace" || articles[1].Content != "Café opening." {
+		t.Errorf("second article = %q, %q", articles[1].Title, articles[1].Content)
+	}
+	// Nothing listens on the cross-post's port; it's kept without content.
+	if articles[2].Title != "A cross-post" || articles[2].Content != "" {
+		t.Errorf("third article = %q, %q", articles[2].Title, articles[2].Content)
+	}
+
+	if want := time.Date(2024, 3, 14, 0, 0, 0, 0, time.Local); !cursor.Since.Equal(want) {
+		t.Errorf("cursor.Since = %v, want %v", cursor.Since, want)
+	}
+	articles, _, err = g.Fetch(context.Background(), feed, cursor)
+	if err != nil {
+		t.Fatalf("second Fetch: %v", err)
+	}
+	if len(articles) != 1 {
+		t.Errorf("second Fetch got %d articles, want only the newest day's 1", len(articles))
+	}
+
+	data, err := os.ReadFile(hosts)
+	if err != nil {
+		t.Fatalf("known hosts not written: %v", err)
+	}
+	if !strings.Contains(string(data), addr+" sha256:") {
+		t.Errorf("known hosts has no pin for %s:\n%s", addr, data)
+	}
+}
+
+func TestGeminiFetch_Atom(t *testing.T) {
+	addr, _ := newGeminiServer(t, map[string]string{
+		"/atom.xml":                   "20 application/atom+xml\r\n" + testGeminiAtom,
+		"/gemlog/2024-03-14-tofu.gmi": "20 text/gemini\r\n" + testPost,
+	})
+	g := &Gemini{}
+
+	articles, _, err := g.Fetch(context.Background(), model.Feed{URL: "gemini://" + addr + "/atom.xml"}, store.Cursor{})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(articles) != 1 {
+		t.Fatalf("got %d articles, want 1", len(articles))
+	}
+	if !strings.HasPrefix(articles[0].Content, "# Trust on first use") {
+		t.Errorf("Content = %q, want the post as Markdown", articles[0].Content)
+	}
+}
+
+func TestGeminiFetch_Errors(t *testing.T) {
+	addr, _ := newGeminiServer(t, map[string]string{
+		"/search": "10 Search terms\r\n",
+		"/loop":   "31 /loop\r\n",
+		"/image":  "20 image/png\r\n\x89PNG",
+	})
+	g := &Gemini{}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/missing", "51 Not found"},
+		{"/search", "asks for input"},
+		{"/loop", "redirects"},
+		{"/image", "unsupported content type"},
+	}
+	for _, tt := range tests {
+		_, _, err := g.Fetch(context.Background(), model.Feed{URL: "gemini://" + addr + tt.path}, store.Cursor{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Fetch(%s) error = %v, want it to mention %q", tt.path, err, tt.want)
+		}
+	}
+}
+
+func TestGeminiVerify(t *testing.T) {
+	addr, cert := newGeminiServer(t, map[string]string{
+		"/": "20 text/gemini\r\n=> /post.gmi 2024-03-14 Post\r\n",
+	})
+	feed := model.Feed{URL: "gemini://" + addr + "/"}
+
+	tests := []struct {
+		name    string
+		pin     string
+		wantErr bool
+	}{
+		{"new host", "", false},
+		{"same certificate", addr + " " + fingerprintOf(cert) + " 2099-01-01T00:00:00Z", false},
+		{"changed certificate", addr + " sha256:00ff 2099-01-01T00:00:00Z", true},
+		{"pinned certificate expired", addr + " sha256:00ff 2020-01-01T00:00:00Z", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts := filepath.Join(t.TempDir(), "known_hosts")
+			if tt.pin != "" {
+				if err := os.WriteFile(hosts, []byte(tt.pin+"\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			g := &Gemini{KnownHostsFile: hosts}
+			_, _, err := g.Fetch(context.Background(), feed, store.Cursor{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fetch error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, err := os.ReadFile(hosts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), addr+" "+fingerprintOf(cert)) {
+				t.Errorf("known hosts doesn't pin the server's certificate:\n%s", data)
+			}
+		})
+	}
+}
+
+func fingerprintOf(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestGemtextMarkdown(t *testing.T) {
+	base, _ := url.Parse("gemini://example.org/gemlog/post.gmi")
+	got := gemtextMarkdown(testPost+"=> ../about.gmi\n> Quoted\nAfter", base)
+	want := "# Trust on first use\n" +
+		"\n" +
+		"Gemini servers mostly sign their own certificates.\n" +
+		"\n" +
+		"Clients pin them instead.\n" +
+		"\n" +
+		"[Wikipedia](https://en.wikipedia.org/wiki/Trust_on_first_use)\n" +
+		"\n" +
+		"- one\n" +
+		"- two\n" +
+		"\n" +
+		"```\n" +
+		"openssl x509 -fingerprint\n" +
+		"```\n" +
+		"\n" +
+		"<gemini://example.org/about.gmi>\n" +
+		"\n" +
+		"> Quoted\n" +
+		"\n" +
+		"After"
+	if got != want {
+		t.Errorf("gemtextMarkdown =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGemfeedArticles_SkipsUndatedLinks(t *testing.T) {
+	base, _ := url.Parse("gemini://example.org/")
+	articles := gemfeedArticles(base, "=> /about.gmi About\n=> /x.gmi 2024-13-01 Bad date\n=> /y.gmi 2024-01-05\n")
+	if len(articles) != 1 {
+		t.Fatalf("got %d articles, want 1", len(articles))
+	}
+	if articles[0].Title != "2024-01-05" {
+		t.Errorf("Title = %q, want the date when there's no title", articles[0].Title)
+	}
+}
@@ -0,0 +1,118 @@
+package feed
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// gemfeedArticles reads a gemtext page as a gemfeed: every link line
+// whose label starts with a date ("=> post.gmi 2024-03-14 - Title") is
+// a post. Other links are navigation and are ignored.
+func gemfeedArticles(base *url.URL, body string) []model.Article {
+	var articles []model.Article
+	for _, line := range gemtextLines(body) {
+		if !strings.HasPrefix(line, "=>") {
+			continue
+		}
+		target, label := gemtextLink(line, base)
+		if target == "" || len(label) < len("2006-01-02") {
+			continue
+		}
+		date, err := time.ParseInLocation("2006-01-02", label[:10], time.Local)
+		if err != nil {
+			continue
+		}
+		title := strings.TrimSpace(strings.TrimLeft(label[10:], " \t-–—:"))
+		if title == "" {
+			title = label[:10]
+		}
+		a := model.Article{GUID: target, URL: target, Title: title, PublishedAt: date}
+		articles = append(articles, normalizeArticle(base.String(), a))
+	}
+	return articles
+}
+
+// gemtextMarkdown converts a gemtext document to Markdown for the
+// reader. Gemtext has no inline markup and every line is its own
+// paragraph, so text lines are separated by blank lines; links become
+// Markdown links resolved against base and preformatted blocks become
+// fenced code.
+func gemtextMarkdown(body string, base *url.URL) string {
+	var b strings.Builder
+	pre, list := false, false
+	for _, line := range gemtextLines(body) {
+		if strings.HasPrefix(line, "```") {
+			if pre {
+				b.WriteString("```\n\n")
+			} else {
+				b.WriteString("\n```\n")
+			}
+			pre = !pre
+			continue
+		}
+		if pre {
+			b.WriteString(line + "\n")
+			continue
+		}
+
+		// WHY: A line right after a list item would continue the item
+		// in Markdown, so the list is closed with a blank line first.
+		if list && !strings.HasPrefix(line, "* ") {
+			b.WriteString("\n")
+		}
+		list = false
+
+		switch {
+		case strings.HasPrefix(line, "=>"):
+			target, label := gemtextLink(line, base)
+			switch {
+			case target == "":
+			case label == "":
+				b.WriteString("<" + target + ">\n\n")
+			default:
+				b.WriteString("[" + label + "](" + target + ")\n\n")
+			}
+		case strings.HasPrefix(line, "#"):
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			text := strings.TrimSpace(line[level:])
+			if text != "" {
+				b.WriteString("\n" + strings.Repeat("#", min(level, 3)) + " " + text + "\n\n")
+			}
+		case strings.HasPrefix(line, "* "):
+			b.WriteString("- " + strings.TrimSpace(line[2:]) + "\n")
+			list = true
+		case strings.HasPrefix(line, ">"):
+			b.WriteString("> " + strings.TrimSpace(line[1:]) + "\n\n")
+		default:
+			// Leading spaces would turn a line into an indented code block.
+			b.WriteString(strings.TrimSpace(line) + "\n\n")
+		}
+	}
+	if pre {
+		b.WriteString("```\n")
+	}
+	return strings.TrimSpace(collapseBlankLines(b.String()))
+}
+
+// gemtextLines splits a gemtext document into lines, which may end in
+// CRLF or LF.
+func gemtextLines(body string) []string {
+	return strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+}
+
+// gemtextLink splits a "=> URL label" line and resolves the URL against
+// base. The label is "" when the line has none.
+func gemtextLink(line string, base *url.URL) (target, label string) {
+	rest := strings.TrimSpace(strings.TrimPrefix(line, "=>"))
+	target = rest
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		target, label = rest[:i], rest[i+1:]
+	}
+	if ref, err := url.Parse(target); err == nil && base != nil {
+		target = base.ResolveReference(ref).String()
+	}
+	return target, strings.TrimSpace(label)
+}
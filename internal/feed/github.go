@@ -2,43 +2,91 @@ package feed
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/go-github/v68/github"
 	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
 )
 
-// FetchGitHubReleases fetches releases from a GitHub repository and
-// maps them to Article structs. The repo string should be "owner/repo".
+const (
+	// releasesPerPage is the page size for release listings.
+	releasesPerPage = 25
+
+	// maxReleasePages caps how far back one refresh will page when
+	// catching up on a busy repo, so a stale cursor can't drain the quota.
+	maxReleasePages = 8
+
+	// githubReserve is how many API calls we keep in hand. Once the
+	// reported remaining quota drops below it, GitHub feeds are deferred
+	// until the limit resets instead of failing one by one.
+	githubReserve = 5
+)
+
+// ErrDeferred is returned for feeds that were skipped this refresh
+// because the GitHub rate limit is nearly exhausted.
+var ErrDeferred = errors.New("deferred: GitHub rate limit nearly exhausted")
+
+// GitHub wraps a GitHub API client shared by every GitHub feed. It is
+// safe for concurrent use and remembers the most recently reported rate
+// limit so the fetcher can hold back requests before hitting it.
+type GitHub struct {
+	// GraphQL batches release feeds into GraphQL queries instead of one
+	// REST call per repo. The GraphQL API requires a token.
+	GraphQL bool
+
+	client     *github.Client
+	token      string
+	graphqlURL string
+	webBase    string
+
+	mu          sync.Mutex
+	rate        github.Rate
+	graphqlRate github.Rate
+}
+
+// NewGitHub creates a GitHub client, optionally authenticated.
 // If token is empty, unauthenticated requests are used (lower rate limit).
-func FetchGitHubReleases(ctx context.Context, repo string, token string) ([]model.Article, error) {
-	parts := strings.SplitN(repo, "/", 2)
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid GitHub repo format %q, expected owner/repo", repo)
+func NewGitHub(token string) *GitHub {
+	return &GitHub{
+		client:     newGitHubClient(token),
+		token:      token,
+		graphqlURL: defaultGraphQLURL,
+		webBase:    "https://github.com",
 	}
-	owner, repoName := parts[0], parts[1]
-
-	client := newGitHubClient(token)
+}
 
-	// LEARN: ListReleases returns paginated results. For a personal
-	// reader we only need the first page (most recent releases).
-	releases, _, err := client.Repositories.ListReleases(ctx, owner, repoName, &github.ListOptions{
-		PerPage: 25,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("fetching releases for %s: %w", repo, err)
+// NewEnterpriseGitHub creates a client for a GitHub Enterprise Server
+// instance. baseURL is the instance root ("https://ghe.example.com", or
+// "https://corp.example.com/github" behind a path prefix); the REST,
+// GraphQL and web URLs are derived from it.
+func NewEnterpriseGitHub(token, baseURL string) (*GitHub, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid GitHub Enterprise URL %q", baseURL)
 	}
+	root := u.Scheme + "://" + u.Host + strings.TrimSuffix(u.Path, "/")
 
-	articles := make([]model.Article, 0, len(releases))
-	for _, rel := range releases {
-		if rel.Draft != nil && *rel.Draft {
-			continue
-		}
-		articles = append(articles, mapRelease(repo, rel))
+	// LEARN: WithEnterpriseURLs appends "/api/v3/" to the base URL, which
+	// is where Enterprise Server serves the REST API.
+	client, err := newGitHubClient(token).WithEnterpriseURLs(root, root)
+	if err != nil {
+		return nil, fmt.Errorf("configuring GitHub Enterprise client: %w", err)
 	}
-	return articles, nil
+	return &GitHub{
+		client:     client,
+		token:      token,
+		graphqlURL: root + "/api/graphql",
+		webBase:    root,
+	}, nil
 }
 
 // newGitHubClient creates a GitHub API client, optionally authenticated.
@@ -51,22 +99,219 @@ func newGitHubClient(token string) *github.Client {
 	return github.NewClient(nil)
 }
 
+// RateLimit returns the rate limit reported by the most recent response.
+// The zero value means no response has reported one yet.
+func (g *GitHub) RateLimit() github.Rate {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rate
+}
+
+// Exhausted reports whether the remaining quota is below the reserve,
+// and if so when it resets.
+func (g *GitHub) Exhausted() (time.Time, bool) {
+	return rateExhausted(g.RateLimit())
+}
+
+// rateExhausted reports whether a rate limit is below the reserve and
+// hasn't reset yet, returning the reset time if so.
+func rateExhausted(rate github.Rate) (time.Time, bool) {
+	if rate.Limit == 0 || rate.Remaining >= githubReserve {
+		return time.Time{}, false
+	}
+	if !time.Now().Before(rate.Reset.Time) {
+		return time.Time{}, false
+	}
+	return rate.Reset.Time, true
+}
+
+// recordRate stores the rate limit from a response, if it carried one.
+func (g *GitHub) recordRate(resp *github.Response) {
+	// WHY: Responses without rate headers (errors before reaching the
+	// API, some proxies) parse as a zero Rate, which would look like an
+	// exhausted quota.
+	if resp == nil || resp.Rate.Limit == 0 {
+		return
+	}
+	g.mu.Lock()
+	g.rate = resp.Rate
+	g.mu.Unlock()
+}
+
+// Releases fetches releases for a repository ("owner/repo") not seen on
+// earlier refreshes and maps them to Article structs. It pages back until
+// it reaches releases it has seen before, and sends the previous ETag so
+// an unchanged repo costs no rate limit. The returned cursor should be
+// stored for the next call.
+func (g *GitHub) Releases(ctx context.Context, repo string, cursor store.Cursor) ([]model.Article, store.Cursor, error) {
+	owner, repoName, err := splitRepo(repo)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	// WHY: A draft published today keeps the ID it got when it was
+	// drafted, and the listing orders by creation, not publication. So
+	// whether a release is new can't be told from where it sorts; the
+	// cursor keeps the IDs of the releases already reported instead.
+	seen := releaseSeen(cursor)
+	first := cursor.Seen == nil && cursor.LatestID == ""
+
+	next := store.Cursor{Seen: make(map[string]string)}
+	var articles []model.Article
+	page := 1
+	for {
+		path := fmt.Sprintf("repos/%s/%s/releases?per_page=%d&page=%d", owner, repoName, releasesPerPage, page)
+		req, err := g.client.NewRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return nil, cursor, fmt.Errorf("building request for %s: %w", repo, err)
+		}
+		if page == 1 && cursor.ETag != "" {
+			// LEARN: GitHub answers a matching If-None-Match with 304 Not
+			// Modified, and conditional 304s don't count against the quota.
+			req.Header.Set("If-None-Match", cursor.ETag)
+		}
+
+		var releases []*github.RepositoryRelease
+		resp, err := g.client.Do(ctx, req, &releases)
+		g.recordRate(resp)
+		if resp != nil && resp.StatusCode == http.StatusNotModified {
+			return nil, cursor, nil
+		}
+		if err != nil {
+			return nil, cursor, fmt.Errorf("fetching releases for %s: %w", repo, err)
+		}
+
+		if page == 1 {
+			next.ETag = resp.Header.Get("ETag")
+		}
+
+		fresh, caughtUp := newReleases(repo, releases, seen, next)
+		articles = append(articles, fresh...)
+
+		// WHY: Without a cursor this is the first fetch — one page of
+		// recent history is enough, there's nothing to catch up on.
+		if caughtUp || first || resp.NextPage == 0 || page >= maxReleasePages {
+			break
+		}
+		page = resp.NextPage
+	}
+	return articles, next, nil
+}
+
+// newReleases maps the releases seen doesn't cover and adds every
+// published one to next's Seen set. It reports whether any of them were
+// seen before.
+//
+// WHY: The whole list is read rather than stopping at the first seen
+// release, since a newly published draft can sort below it. Drafts stay
+// out of the seen set so they're reported once they're published.
+func newReleases(repo string, releases []*github.RepositoryRelease, seen func(int64) bool, next store.Cursor) ([]model.Article, bool) {
+	caughtUp := false
+	var articles []model.Article
+	for _, rel := range releases {
+		if rel.GetDraft() {
+			continue
+		}
+		next.Seen[strconv.FormatInt(rel.GetID(), 10)] = ""
+		if seen(rel.GetID()) {
+			caughtUp = true
+			continue
+		}
+		articles = append(articles, mapRelease(repo, rel))
+	}
+	return articles, caughtUp
+}
+
+// releaseSeen returns a test for whether a release was reported by an
+// earlier refresh.
+func releaseSeen(cursor store.Cursor) func(id int64) bool {
+	if cursor.Seen != nil {
+		return func(id int64) bool {
+			_, ok := cursor.Seen[strconv.FormatInt(id, 10)]
+			return ok
+		}
+	}
+	// Cursors from before Seen was kept hold the highest release ID.
+	latest, _ := strconv.ParseInt(cursor.LatestID, 10, 64)
+	return func(id int64) bool {
+		return latest != 0 && id <= latest
+	}
+}
+
+// HasReleases reports whether a repository publishes GitHub Releases.
+// Projects that only push tags need a github-tags: feed instead.
+func (g *GitHub) HasReleases(ctx context.Context, repo string) (bool, error) {
+	owner, repoName, err := splitRepo(repo)
+	if err != nil {
+		return false, err
+	}
+	releases, resp, err := g.client.Repositories.ListReleases(ctx, owner, repoName, &github.ListOptions{PerPage: 1})
+	g.recordRate(resp)
+	if err != nil {
+		return false, fmt.Errorf("listing releases for %s: %w", repo, err)
+	}
+	return len(releases) > 0, nil
+}
+
+// splitRepo splits an "owner/repo" string into its two parts.
+func splitRepo(repo string) (string, string, error) {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid GitHub repo format %q, expected owner/repo", repo)
+	}
+	return parts[0], parts[1], nil
+}
+
 // mapRelease converts a GitHub release to an Article.
 func mapRelease(repo string, rel *github.RepositoryRelease) model.Article {
+	info := releaseInfo{
+		guid:       fmt.Sprintf("github:%s:%d", repo, rel.GetID()),
+		repo:       repo,
+		name:       rel.GetName(),
+		tag:        rel.GetTagName(),
+		url:        rel.GetHTMLURL(),
+		body:       rel.GetBody(),
+		prerelease: rel.GetPrerelease(),
+	}
+	if rel.Author != nil {
+		info.author = rel.Author.GetLogin()
+	}
+	if rel.PublishedAt != nil {
+		info.publishedAt = rel.PublishedAt.Time
+	}
+	if rel.CreatedAt != nil {
+		info.createdAt = rel.CreatedAt.Time
+	}
+	return info.article()
+}
+
+// releaseInfo is a forge-neutral release. Every forge source maps its
+// API type into one so releases look the same whichever forge they
+// come from.
+type releaseInfo struct {
+	guid, repo, name, tag, url, author, body string
+	prerelease                               bool
+	publishedAt, createdAt                   time.Time
+}
+
+// article converts the release to an Article.
+func (r releaseInfo) article() model.Article {
 	a := model.Article{
-		GUID:    fmt.Sprintf("github:%s:%d", repo, rel.GetID()),
-		FeedURL: "github:" + repo,
-		Title:   releaseTitle(repo, rel),
-		URL:     rel.GetHTMLURL(),
-		Author:  releaseAuthor(rel),
-		Summary: truncate(rel.GetBody(), 200),
-		Content: rel.GetBody(),
+		GUID:    r.guid,
+		Title:   releaseTitle(r.repo, r.name, r.tag),
+		URL:     r.url,
+		Author:  r.author,
+		Summary: truncate(r.body, 200),
+		Content: r.body,
+
+		Version:    r.tag,
+		Prerelease: r.prerelease,
 	}
 
-	if rel.PublishedAt != nil {
-		a.PublishedAt = rel.PublishedAt.Time
-	} else if rel.CreatedAt != nil {
-		a.PublishedAt = rel.CreatedAt.Time
+	if !r.publishedAt.IsZero() {
+		a.PublishedAt = r.publishedAt
+	} else if !r.createdAt.IsZero() {
+		a.PublishedAt = r.createdAt
 	} else {
 		a.PublishedAt = time.Now()
 	}
@@ -78,10 +323,7 @@ func mapRelease(repo string, rel *github.RepositoryRelease) model.Article {
 }
 
 // releaseTitle builds a human-readable title from the release.
-func releaseTitle(repo string, rel *github.RepositoryRelease) string {
-	name := rel.GetName()
-	tag := rel.GetTagName()
-
+func releaseTitle(repo, name, tag string) string {
 	if name != "" {
 		return name
 	}
@@ -93,18 +335,16 @@ func releaseTitle(repo string, rel *github.RepositoryRelease) string {
 	return repo + " release"
 }
 
-// releaseAuthor extracts the author login from the release.
-func releaseAuthor(rel *github.RepositoryRelease) string {
-	if rel.Author != nil {
-		return rel.Author.GetLogin()
-	}
-	return ""
-}
-
 // truncate shortens a string to maxLen, adding "..." if truncated.
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	// WHY: Back up to a rune boundary so multi-byte characters (common
+	// in social posts) aren't cut in half.
+	end := maxLen - 3
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + "..."
 }
@@ -0,0 +1,82 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+func TestParseCommitTarget(t *testing.T) {
+	tests := []struct {
+		in   string
+		want commitTarget
+	}{
+		{"golang/go@master:src/net/http", commitTarget{"golang", "go", "master", "src/net/http"}},
+		{"golang/go@master", commitTarget{"golang", "go", "master", ""}},
+		{"golang/go:/src/net/http/", commitTarget{"golang", "go", "", "src/net/http"}},
+		{"golang/go", commitTarget{"golang", "go", "", ""}},
+	}
+	for _, tt := range tests {
+		got, err := parseCommitTarget(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseCommitTarget(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+	}
+
+	if _, err := parseCommitTarget("no-slash@main"); err == nil {
+		t.Error("expected error for target without owner/repo")
+	}
+}
+
+func TestCommits(t *testing.T) {
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	g := newTestGitHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/repos/golang/go/commits" || q.Get("sha") != "master" || q.Get("path") != "src/net/http" {
+			t.Errorf("request = %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		if q.Get("since") != since.Format(time.RFC3339) {
+			t.Errorf("since = %q, want %s", q.Get("since"), since.Format(time.RFC3339))
+		}
+		fmt.Fprint(w, `[
+			{"sha":"abcdef1234567890","html_url":"https://github.com/golang/go/commit/abcdef1234567890",
+			 "author":{"login":"gopher"},
+			 "commit":{"message":"net/http: fix header parsing\n\nFixes #1.","committer":{"date":"2025-03-03T10:00:00Z"}}},
+			{"sha":"1234567890abcdef","html_url":"https://github.com/golang/go/commit/1234567890abcdef",
+			 "commit":{"message":"net/http: tidy","author":{"name":"Anon"},"committer":{"date":"2025-03-02T10:00:00Z"}}}
+		]`)
+	}))
+
+	articles, cursor, err := g.Commits(t.Context(), "golang/go@master:src/net/http", store.Cursor{Since: since})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("articles = %d, want 2", len(articles))
+	}
+
+	a := articles[0]
+	if a.Title != "net/http: fix header parsing" {
+		t.Errorf("Title = %q, want subject line", a.Title)
+	}
+	if a.Content != "net/http: fix header parsing\n\nFixes #1." {
+		t.Errorf("Content = %q", a.Content)
+	}
+	if a.URL != "https://github.com/golang/go/commit/abcdef1234567890" || a.Author != "gopher" {
+		t.Errorf("URL/Author = %q / %q", a.URL, a.Author)
+	}
+	if a.Summary != "golang/go@abcdef1" {
+		t.Errorf("Summary = %q", a.Summary)
+	}
+	if articles[1].Author != "Anon" {
+		t.Errorf("fallback author = %q", articles[1].Author)
+	}
+
+	want := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+	if !cursor.Since.Equal(want) {
+		t.Errorf("cursor.Since = %v, want newest commit date %v", cursor.Since, want)
+	}
+}
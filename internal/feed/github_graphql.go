@@ -0,0 +1,269 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+const (
+	// defaultGraphQLURL is the GitHub GraphQL endpoint for github.com.
+	defaultGraphQLURL = "https://api.github.com/graphql"
+
+	// graphqlBatchSize is how many repositories go into one query. The
+	// API caps a query's node count, so very large batches get rejected.
+	graphqlBatchSize = 50
+
+	// graphqlReleasesPerRepo is how many of each repo's latest releases
+	// a batched query asks for. It matches a REST page, so both modes
+	// leave the same seen set behind.
+	graphqlReleasesPerRepo = releasesPerPage
+)
+
+// BatchResult holds one repository's outcome from a batched query.
+type BatchResult struct {
+	Repo string
+	// Releases are the repo's latest releases, newest first, drafts
+	// included.
+	Releases []*github.RepositoryRelease
+	// More reports whether the repo has releases beyond Releases.
+	More bool
+	Err  error
+}
+
+// Since returns the batched releases the cursor hasn't seen and the
+// cursor to store, as Releases would. It reports false when none of the
+// batched releases were seen before and older ones exist, since more
+// new releases may be among those; the feed has to page back over REST.
+func (r BatchResult) Since(cursor store.Cursor) ([]model.Article, store.Cursor, bool) {
+	next := store.Cursor{ETag: cursor.ETag, Seen: make(map[string]string)}
+	articles, caughtUp := newReleases(r.Repo, r.Releases, releaseSeen(cursor), next)
+	first := cursor.Seen == nil && cursor.LatestID == ""
+	if !caughtUp && !first && r.More {
+		return nil, cursor, false
+	}
+	return articles, next, true
+}
+
+// graphqlRelease mirrors the release fields requested in the query.
+type graphqlRelease struct {
+	DatabaseID   int64      `json:"databaseId"`
+	Name         string     `json:"name"`
+	TagName      string     `json:"tagName"`
+	URL          string     `json:"url"`
+	Description  string     `json:"description"`
+	IsDraft      bool       `json:"isDraft"`
+	IsPrerelease bool       `json:"isPrerelease"`
+	PublishedAt  *time.Time `json:"publishedAt"`
+	CreatedAt    *time.Time `json:"createdAt"`
+	Author       *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// graphqlResponse is the envelope of a batched releases query. Each repo
+// is queried under an alias ("r0", "r1", ...) so data is decoded lazily.
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+		Path    []any  `json:"path"`
+	} `json:"errors"`
+}
+
+// BatchReleases fetches the latest releases for many repositories
+// ("owner/repo") with one GraphQL query per batch. Results are keyed by
+// repo; a repo that fails (missing, renamed) carries its own error
+// without failing the rest of the batch.
+func (g *GitHub) BatchReleases(ctx context.Context, repos []string) map[string]BatchResult {
+	results := make(map[string]BatchResult, len(repos))
+	for start := 0; start < len(repos); start += graphqlBatchSize {
+		end := min(start+graphqlBatchSize, len(repos))
+		g.batchReleases(ctx, repos[start:end], results)
+	}
+	return results
+}
+
+// batchReleases runs one GraphQL query for up to graphqlBatchSize repos
+// and records each repo's result.
+func (g *GitHub) batchReleases(ctx context.Context, repos []string, results map[string]BatchResult) {
+	fail := func(err error) {
+		for _, repo := range repos {
+			results[repo] = BatchResult{Err: err}
+		}
+	}
+
+	if reset, low := g.graphqlExhausted(); low {
+		fail(fmt.Errorf("%w until %s", ErrDeferred, reset.Local().Format(time.Kitchen)))
+		return
+	}
+
+	query, err := releasesQuery(repos)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	resp, err := g.graphql(ctx, query)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	repoErrs := make(map[string]error)
+	for _, e := range resp.Errors {
+		if len(e.Path) > 0 {
+			if alias, ok := e.Path[0].(string); ok {
+				repoErrs[alias] = fmt.Errorf("github graphql: %s", e.Message)
+			}
+		}
+	}
+
+	for i, repo := range repos {
+		alias := "r" + strconv.Itoa(i)
+		if err := repoErrs[alias]; err != nil {
+			results[repo] = BatchResult{Err: err}
+			continue
+		}
+
+		var node struct {
+			Releases struct {
+				Nodes    []graphqlRelease `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool `json:"hasNextPage"`
+				} `json:"pageInfo"`
+			} `json:"releases"`
+		}
+		raw := resp.Data[alias]
+		if len(raw) == 0 || string(raw) == "null" {
+			results[repo] = BatchResult{Err: fmt.Errorf("github graphql: repository %s not found", repo)}
+			continue
+		}
+		if err := json.Unmarshal(raw, &node); err != nil {
+			results[repo] = BatchResult{Err: fmt.Errorf("decoding releases for %s: %w", repo, err)}
+			continue
+		}
+
+		res := BatchResult{Repo: repo, More: node.Releases.PageInfo.HasNextPage}
+		for _, n := range node.Releases.Nodes {
+			res.Releases = append(res.Releases, n.toRelease())
+		}
+		results[repo] = res
+	}
+}
+
+// releasesQuery builds one GraphQL query that fetches the latest
+// releases of every repo under its own alias, plus the rate limit.
+func releasesQuery(repos []string) (string, error) {
+	var b strings.Builder
+	b.WriteString("query {\n  rateLimit { limit remaining resetAt }\n")
+	for i, repo := range repos {
+		owner, name, err := splitRepo(repo)
+		if err != nil {
+			return "", err
+		}
+		// WHY: JSON string escaping is a valid GraphQL string literal,
+		// which keeps odd characters in repo names from breaking the query.
+		o, _ := json.Marshal(owner)
+		n, _ := json.Marshal(name)
+		fmt.Fprintf(&b, "  r%d: repository(owner: %s, name: %s) {\n", i, o, n)
+		fmt.Fprintf(&b, "    releases(first: %d, orderBy: {field: CREATED_AT, direction: DESC}) {\n", graphqlReleasesPerRepo)
+		b.WriteString("      nodes { databaseId name tagName url description isDraft isPrerelease publishedAt createdAt author { login } }\n")
+		b.WriteString("      pageInfo { hasNextPage }\n")
+		b.WriteString("    }\n  }\n")
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// graphql posts a query to the GraphQL endpoint and records the rate
+// limit it reports.
+func (g *GitHub) graphql(ctx context.Context, query string) (*graphqlResponse, error) {
+	if g.token == "" {
+		return nil, fmt.Errorf("github graphql: a github_token is required")
+	}
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		return nil, fmt.Errorf("encoding graphql query: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.graphqlURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("building graphql request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+g.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.client.Client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("github graphql: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github graphql: unexpected status %s", resp.Status)
+	}
+
+	var out graphqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decoding graphql response: %w", err)
+	}
+
+	var rate struct {
+		Limit     int       `json:"limit"`
+		Remaining int       `json:"remaining"`
+		ResetAt   time.Time `json:"resetAt"`
+	}
+	if raw, ok := out.Data["rateLimit"]; ok && json.Unmarshal(raw, &rate) == nil && rate.Limit > 0 {
+		g.mu.Lock()
+		g.graphqlRate = github.Rate{
+			Limit:     rate.Limit,
+			Remaining: rate.Remaining,
+			Reset:     github.Timestamp{Time: rate.ResetAt},
+		}
+		g.mu.Unlock()
+	}
+	return &out, nil
+}
+
+// graphqlExhausted is Exhausted for the GraphQL quota, which GitHub
+// tracks separately from the REST one.
+func (g *GitHub) graphqlExhausted() (time.Time, bool) {
+	g.mu.Lock()
+	rate := g.graphqlRate
+	g.mu.Unlock()
+	return rateExhausted(rate)
+}
+
+// toRelease converts a GraphQL release node into the REST type so both
+// modes share mapRelease.
+func (n graphqlRelease) toRelease() *github.RepositoryRelease {
+	rel := &github.RepositoryRelease{
+		ID:         github.Ptr(n.DatabaseID),
+		Name:       github.Ptr(n.Name),
+		TagName:    github.Ptr(n.TagName),
+		HTMLURL:    github.Ptr(n.URL),
+		Body:       github.Ptr(n.Description),
+		Draft:      github.Ptr(n.IsDraft),
+		Prerelease: github.Ptr(n.IsPrerelease),
+	}
+	if n.PublishedAt != nil {
+		rel.PublishedAt = &github.Timestamp{Time: *n.PublishedAt}
+	}
+	if n.CreatedAt != nil {
+		rel.CreatedAt = &github.Timestamp{Time: *n.CreatedAt}
+	}
+	if n.Author != nil {
+		rel.Author = &github.User{Login: github.Ptr(n.Author.Login)}
+	}
+	return rel
+}
@@ -0,0 +1,178 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+// aliasPattern picks the per-repo aliases out of a batched query.
+var aliasPattern = regexp.MustCompile(`(r\d+): repository\(owner: "([^"]+)", name: "([^"]+)"\)`)
+
+// fakeGraphQL serves batched release queries from a map of
+// "owner/repo" to release nodes. Repos missing from the map answer
+// the way GitHub does: a null alias plus an error pointing at it.
+func fakeGraphQL(t *testing.T, releases map[string]string, requests *int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if got := r.Header.Get("Authorization"); !strings.EqualFold(got, "bearer test-token") {
+			t.Errorf("Authorization = %q", got)
+		}
+
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+
+		data := []string{`"rateLimit":{"limit":5000,"remaining":4990,"resetAt":"2099-01-01T00:00:00Z"}`}
+		var errs []string
+		for _, m := range aliasPattern.FindAllStringSubmatch(body.Query, -1) {
+			alias, repo := m[1], m[2]+"/"+m[3]
+			nodes, ok := releases[repo]
+			if !ok {
+				data = append(data, fmt.Sprintf(`%q:null`, alias))
+				errs = append(errs, fmt.Sprintf(`{"type":"NOT_FOUND","path":[%q],"message":"Could not resolve to a Repository with the name '%s'."}`, alias, repo))
+				continue
+			}
+			data = append(data, fmt.Sprintf(`%q:{"releases":{"nodes":[%s],"pageInfo":{"hasNextPage":true}}}`, alias, nodes))
+		}
+
+		fmt.Fprintf(w, `{"data":{%s}`, strings.Join(data, ","))
+		if len(errs) > 0 {
+			fmt.Fprintf(w, `,"errors":[%s]`, strings.Join(errs, ","))
+		}
+		fmt.Fprint(w, "}")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestBatchReleases(t *testing.T) {
+	var requests int
+	srv := fakeGraphQL(t, map[string]string{
+		"tokio-rs/tokio": `{"databaseId":2,"tagName":"v1.2.0","url":"https://github.com/tokio-rs/tokio/releases/tag/v1.2.0","publishedAt":"2025-01-15T10:00:00Z","author":{"login":"carllerche"}},
+			{"databaseId":1,"tagName":"v1.1.0","isDraft":true}`,
+		"golang/go": `{"databaseId":9,"name":"Go 1.24","tagName":"go1.24","description":"Release notes."}`,
+	}, &requests)
+
+	g := NewGitHub("test-token")
+	g.graphqlURL = srv.URL
+
+	results := g.BatchReleases(t.Context(), []string{"tokio-rs/tokio", "golang/go", "gone/missing"})
+
+	if requests != 1 {
+		t.Errorf("requests = %d, want one batched query", requests)
+	}
+
+	tokio := results["tokio-rs/tokio"]
+	if tokio.Err != nil {
+		t.Fatalf("tokio error: %v", tokio.Err)
+	}
+	articles, cursor, ok := tokio.Since(store.Cursor{})
+	if !ok {
+		t.Fatal("a first fetch should take the batch as it is")
+	}
+	if len(articles) != 1 {
+		t.Fatalf("tokio articles = %d, want 1 (draft skipped)", len(articles))
+	}
+	a := articles[0]
+	if a.Title != "tokio-rs/tokio v1.2.0" {
+		t.Errorf("Title = %q, want tag fallback like mapRelease", a.Title)
+	}
+	if a.GUID != "github:tokio-rs/tokio:2" {
+		t.Errorf("GUID = %q", a.GUID)
+	}
+	if a.Author != "carllerche" {
+		t.Errorf("Author = %q", a.Author)
+	}
+	if _, ok := cursor.Seen["2"]; !ok || len(cursor.Seen) != 1 {
+		t.Errorf("Seen = %v, want the published release only", cursor.Seen)
+	}
+
+	if got, _, _ := results["golang/go"].Since(store.Cursor{}); len(got) != 1 || got[0].Title != "Go 1.24" || got[0].Content != "Release notes." {
+		t.Errorf("golang/go articles = %+v", got)
+	}
+
+	if results["gone/missing"].Err == nil {
+		t.Error("missing repo should carry its own error")
+	}
+
+	if rate := g.graphqlRate; rate.Remaining != 4990 {
+		t.Errorf("graphql rate remaining = %d, want 4990", rate.Remaining)
+	}
+}
+
+func TestBatchReleases_SplitsLargeBatches(t *testing.T) {
+	var requests int
+	releases := make(map[string]string)
+	var repos []string
+	for i := range graphqlBatchSize + 5 {
+		repo := fmt.Sprintf("owner/repo%d", i)
+		repos = append(repos, repo)
+		releases[repo] = fmt.Sprintf(`{"databaseId":%d,"tagName":"v1"}`, i+1)
+	}
+	srv := fakeGraphQL(t, releases, &requests)
+
+	g := NewGitHub("test-token")
+	g.graphqlURL = srv.URL
+
+	results := g.BatchReleases(t.Context(), repos)
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+	if len(results) != len(repos) {
+		t.Errorf("results = %d, want %d", len(results), len(repos))
+	}
+	for repo, res := range results {
+		if res.Err != nil || len(res.Releases) != 1 {
+			t.Errorf("%s: %+v", repo, res)
+		}
+	}
+}
+
+func TestBatchReleases_RequiresToken(t *testing.T) {
+	g := NewGitHub("")
+	results := g.BatchReleases(t.Context(), []string{"owner/repo"})
+	if results["owner/repo"].Err == nil {
+		t.Error("expected error without a token")
+	}
+}
+
+func TestBatchResult_Since(t *testing.T) {
+	res := BatchResult{
+		Repo: "owner/repo",
+		Releases: []*github.RepositoryRelease{
+			{ID: github.Ptr(int64(30)), TagName: github.Ptr("v0.30.0")},
+			{ID: github.Ptr(int64(29)), TagName: github.Ptr("v0.29.0")},
+		},
+		More: true,
+	}
+
+	// Neither release was seen, and older ones exist: the batch can't
+	// tell whether more new releases are among them.
+	stale := store.Cursor{Seen: map[string]string{"12": ""}}
+	if _, next, ok := res.Since(stale); ok || len(next.Seen) != 1 {
+		t.Errorf("Since(stale) = ok %v, want false and the cursor unchanged", ok)
+	}
+
+	articles, next, ok := res.Since(store.Cursor{Seen: map[string]string{"29": ""}})
+	if !ok {
+		t.Fatal("a batch reaching a seen release should be used")
+	}
+	if len(articles) != 1 || articles[0].GUID != "github:owner/repo:30" {
+		t.Errorf("articles = %+v, want only release 30", articles)
+	}
+	if len(next.Seen) != 2 {
+		t.Errorf("Seen = %v, want both releases", next.Seen)
+	}
+}
@@ -0,0 +1,102 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// searchResultsPerPage is how many of the most recently updated matches
+// a search feed reads per refresh.
+const searchResultsPerPage = 30
+
+// SearchIssues runs a GitHub issue/PR search (the same syntax as the
+// search box, e.g. "repo:golang/go is:issue label:breaking-change") and
+// maps each match to an Article, most recently updated first.
+func (g *GitHub) SearchIssues(ctx context.Context, query string) ([]model.Article, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("empty GitHub search query")
+	}
+
+	// WHY: Search has its own, much smaller quota (30 requests/minute
+	// authenticated) that resets within the minute, so its responses
+	// aren't recorded — they'd make the core quota look exhausted.
+	result, _, err := g.client.Search.Issues(ctx, query, &github.SearchOptions{
+		Sort:        "updated",
+		Order:       "desc",
+		ListOptions: github.ListOptions{PerPage: searchResultsPerPage},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching GitHub for %q: %w", query, err)
+	}
+
+	articles := make([]model.Article, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		articles = append(articles, mapIssue(issue))
+	}
+	return articles, nil
+}
+
+// mapIssue converts an issue or pull request search hit to an Article.
+func mapIssue(issue *github.Issue) model.Article {
+	repo := issueRepo(issue)
+	a := model.Article{
+		Title:   fmt.Sprintf("%s#%d: %s", repo, issue.GetNumber(), issue.GetTitle()),
+		URL:     issue.GetHTMLURL(),
+		Author:  issue.GetUser().GetLogin(),
+		Summary: truncate(issue.GetBody(), 200),
+		Content: issueContent(issue),
+	}
+
+	// WHY: Ordering by update time is the point of a search feed — a
+	// two-year-old issue that just got the label is news today.
+	if issue.UpdatedAt != nil {
+		a.PublishedAt = issue.UpdatedAt.Time
+	} else if issue.CreatedAt != nil {
+		a.PublishedAt = issue.CreatedAt.Time
+	} else {
+		a.PublishedAt = time.Now()
+	}
+	// WHY: One article per issue. New activity refreshes the cached
+	// article in place (see updatedSources) instead of adding a copy.
+	a.GUID = "github-search:" + issue.GetHTMLURL()
+
+	a.FetchedAt = time.Now()
+	return a
+}
+
+// issueRepo returns "owner/repo" for a search hit, taken from its API
+// repository URL.
+func issueRepo(issue *github.Issue) string {
+	u := issue.GetRepositoryURL()
+	if i := strings.Index(u, "/repos/"); i >= 0 {
+		return u[i+len("/repos/"):]
+	}
+	return ""
+}
+
+// issueContent renders the state, kind and labels above the body.
+func issueContent(issue *github.Issue) string {
+	kind := "issue"
+	if issue.IsPullRequest() {
+		kind = "pull request"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "- **State**: %s %s\n", issue.GetState(), kind)
+	if len(issue.Labels) > 0 {
+		names := make([]string, 0, len(issue.Labels))
+		for _, l := range issue.Labels {
+			names = append(names, l.GetName())
+		}
+		fmt.Fprintf(&b, "- **Labels**: %s\n", strings.Join(names, ", "))
+	}
+	if body := issue.GetBody(); body != "" {
+		b.WriteString("\n" + body)
+	}
+	return b.String()
+}
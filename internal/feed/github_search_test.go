@@ -0,0 +1,71 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSearchIssues(t *testing.T) {
+	g := newTestGitHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/issues" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("q") != "repo:golang/go label:breaking-change" || q.Get("sort") != "updated" || q.Get("order") != "desc" {
+			t.Errorf("query = %v", q)
+		}
+		fmt.Fprint(w, `{"total_count":2,"items":[
+			{"number":70000,"title":"net/http: drop legacy flag","state":"open",
+			 "html_url":"https://github.com/golang/go/issues/70000",
+			 "repository_url":"https://api.github.com/repos/golang/go",
+			 "user":{"login":"gopher"},"body":"We should remove it.",
+			 "labels":[{"name":"breaking-change"},{"name":"NeedsDecision"}],
+			 "created_at":"2024-01-01T00:00:00Z","updated_at":"2025-03-01T12:00:00Z"},
+			{"number":70001,"title":"all: update deps","state":"closed",
+			 "html_url":"https://github.com/golang/go/pull/70001",
+			 "repository_url":"https://api.github.com/repos/golang/go",
+			 "pull_request":{"url":"https://api.github.com/repos/golang/go/pulls/70001"},
+			 "user":{"login":"bot"},"updated_at":"2025-02-01T00:00:00Z"}
+		]}`)
+	}))
+
+	articles, err := g.SearchIssues(t.Context(), "repo:golang/go label:breaking-change")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("articles = %d, want 2", len(articles))
+	}
+
+	issue := articles[0]
+	if issue.Title != "golang/go#70000: net/http: drop legacy flag" {
+		t.Errorf("Title = %q", issue.Title)
+	}
+	if issue.GUID != "github-search:https://github.com/golang/go/issues/70000" {
+		t.Errorf("GUID = %q", issue.GUID)
+	}
+	if issue.Author != "gopher" {
+		t.Errorf("Author = %q", issue.Author)
+	}
+	if issue.PublishedAt.Format("2006-01-02") != "2025-03-01" {
+		t.Errorf("PublishedAt = %v, want the updated time", issue.PublishedAt)
+	}
+	for _, want := range []string{"open issue", "breaking-change, NeedsDecision", "We should remove it."} {
+		if !strings.Contains(issue.Content, want) {
+			t.Errorf("Content missing %q:\n%s", want, issue.Content)
+		}
+	}
+
+	if !strings.Contains(articles[1].Content, "closed pull request") {
+		t.Errorf("PR content = %q", articles[1].Content)
+	}
+}
+
+func TestSearchIssues_EmptyQuery(t *testing.T) {
+	g := NewGitHub("")
+	if _, err := g.SearchIssues(t.Context(), "  "); err == nil {
+		t.Error("expected error for empty query")
+	}
+}
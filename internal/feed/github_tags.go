@@ -0,0 +1,152 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v68/github"
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/release"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+const (
+	// tagsPerPage is the most tags the listing returns per page.
+	tagsPerPage = 100
+
+	// maxTagPages bounds how far the listing is paged for repos with
+	// thousands of tags (per-commit or nightly tags).
+	maxTagPages = 20
+
+	// maxNewTags caps how many tags are dated per refresh. Each date is
+	// a commit lookup, so the first fetch of a tag-heavy repo would
+	// otherwise spend the quota on history nobody will read.
+	maxNewTags = 20
+)
+
+// Tags fetches git tags for a repository ("owner/repo") that has no
+// GitHub Releases, and maps the tags missing from the cursor's Seen set
+// to Articles. Each tag is dated by its commit and links to the compare
+// view against the previous tag.
+func (g *GitHub) Tags(ctx context.Context, repo string, cursor store.Cursor) ([]model.Article, store.Cursor, error) {
+	owner, repoName, err := splitRepo(repo)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	// WHY: The listing is ordered by ref name, not by version or date,
+	// so a new tag can land on any page and the whole listing is read.
+	var tags []*github.RepositoryTag
+	var etag string
+	for page := 1; page != 0 && page <= maxTagPages; {
+		path := fmt.Sprintf("repos/%s/%s/tags?per_page=%d&page=%d", owner, repoName, tagsPerPage, page)
+		req, err := g.client.NewRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return nil, cursor, fmt.Errorf("building request for %s: %w", repo, err)
+		}
+		// Cursors from before Seen was kept may hold the ETag of the
+		// first of several pages.
+		if page == 1 && cursor.ETag != "" && cursor.Seen != nil {
+			req.Header.Set("If-None-Match", cursor.ETag)
+		}
+
+		var batch []*github.RepositoryTag
+		resp, err := g.client.Do(ctx, req, &batch)
+		g.recordRate(resp)
+		if page == 1 && resp != nil && resp.StatusCode == http.StatusNotModified {
+			return nil, cursor, nil
+		}
+		if err != nil {
+			return nil, cursor, fmt.Errorf("fetching tags for %s: %w", repo, err)
+		}
+		tags = append(tags, batch...)
+
+		// WHY: A 304 only vouches for the page it was asked about, so
+		// the ETag is kept only when the listing fits on one page.
+		if page == 1 && resp.NextPage == 0 {
+			etag = resp.Header.Get("ETag")
+		}
+		page = resp.NextPage
+	}
+
+	sortTags(tags)
+	seen := seenBefore(cursor)
+	names := make([]string, 0, len(tags))
+	var articles []model.Article
+	for i, tag := range tags {
+		names = append(names, tag.GetName())
+		if seen(tag.GetName()) || len(articles) == maxNewTags {
+			continue
+		}
+
+		commit, commitResp, err := g.client.Git.GetCommit(ctx, owner, repoName, tag.GetCommit().GetSHA())
+		g.recordRate(commitResp)
+		if err != nil {
+			return nil, cursor, fmt.Errorf("resolving tag %s of %s: %w", tag.GetName(), repo, err)
+		}
+
+		var prev string
+		if i+1 < len(tags) {
+			prev = tags[i+1].GetName()
+		}
+		articles = append(articles, g.mapTag(repo, tag, prev, commit))
+	}
+
+	return articles, store.Cursor{ETag: etag, Seen: seenSet(names)}, nil
+}
+
+// sortTags orders tags newest version first. Tags without a version
+// number keep their API order after the versioned ones.
+func sortTags(tags []*github.RepositoryTag) {
+	// WHY: The tags API orders by ref name, which puts v1.10.0 before
+	// v1.9.0 and mixes prefixes. Version order puts the newest tags
+	// first when maxNewTags cuts in, and makes "previous tag" mean what
+	// it should.
+	sort.SliceStable(tags, func(i, j int) bool {
+		a, aok := release.Parse(tags[i].GetName())
+		b, bok := release.Parse(tags[j].GetName())
+		if aok && bok {
+			return release.Compare(a, b) > 0
+		}
+		return aok && !bok
+	})
+}
+
+// mapTag converts a tag and the commit it points at into an Article.
+func (g *GitHub) mapTag(repo string, tag *github.RepositoryTag, prev string, commit *github.Commit) model.Article {
+	name := tag.GetName()
+	a := model.Article{
+		GUID:    fmt.Sprintf("github-tags:%s:%s", repo, name),
+		Title:   repo + " " + name,
+		URL:     g.webURL(repo, "releases/tag/"+name),
+		Author:  commit.GetAuthor().GetName(),
+		Content: commit.GetMessage(),
+		Version: name,
+	}
+	if prev != "" {
+		a.URL = g.webURL(repo, fmt.Sprintf("compare/%s...%s", prev, name))
+	}
+	a.Summary = truncate(a.Content, 200)
+
+	// WHY: Prefer the committer date — for rebased or cherry-picked
+	// commits it's when the change actually landed on the branch.
+	if date := commit.GetCommitter().GetDate(); !date.IsZero() {
+		a.PublishedAt = date.Time
+	} else if date := commit.GetAuthor().GetDate(); !date.IsZero() {
+		a.PublishedAt = date.Time
+	} else {
+		a.PublishedAt = time.Now()
+	}
+
+	a.FetchedAt = time.Now()
+	a.NormalizedTitle = NormalizeTitle(a.Title)
+	return a
+}
+
+// webURL builds a web link for a repo path on this GitHub instance.
+func (g *GitHub) webURL(repo, path string) string {
+	return fmt.Sprintf("%s/%s/%s", g.webBase, repo, path)
+}
@@ -0,0 +1,159 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+// tagsAPI fakes the tags listing, two tags per page, and the git commit
+// endpoint. Commit SHAs are "sha-<tag>".
+func tagsAPI(t *testing.T, tags []string, commitLookups *[]string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/lib/tags", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		lo, hi := min(2*(page-1), len(tags)), min(2*page, len(tags))
+		var items []string
+		for _, tag := range tags[lo:hi] {
+			items = append(items, fmt.Sprintf(`{"name":%q,"commit":{"sha":"sha-%s"}}`, tag, tag))
+		}
+		if hi < len(tags) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, page+1))
+		}
+		w.Header().Set("ETag", fmt.Sprintf(`"tags-%d"`, page))
+		fmt.Fprintf(w, "[%s]", strings.Join(items, ","))
+	})
+	mux.HandleFunc("/repos/owner/lib/git/commits/", func(w http.ResponseWriter, r *http.Request) {
+		sha := strings.TrimPrefix(r.URL.Path, "/repos/owner/lib/git/commits/")
+		*commitLookups = append(*commitLookups, sha)
+		fmt.Fprintf(w, `{"sha":%q,"message":"Release %s","author":{"name":"Gopher"},"committer":{"date":"2025-01-02T03:04:05Z"}}`,
+			sha, strings.TrimPrefix(sha, "sha-"))
+	})
+	return mux
+}
+
+func TestTags_FirstFetch(t *testing.T) {
+	var lookups []string
+	// API order is by ref name, not version.
+	g := newTestGitHub(t, tagsAPI(t, []string{"v1.9.0", "v1.10.0", "v1.8.2"}, &lookups))
+
+	articles, cursor, err := g.Tags(t.Context(), "owner/lib", store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("articles = %d, want 3", len(articles))
+	}
+
+	newest := articles[0]
+	if newest.Title != "owner/lib v1.10.0" || newest.Version != "v1.10.0" {
+		t.Errorf("newest = %q (%q), want v1.10.0 first", newest.Title, newest.Version)
+	}
+	if newest.URL != "https://github.com/owner/lib/compare/v1.9.0...v1.10.0" {
+		t.Errorf("URL = %q, want compare against previous tag", newest.URL)
+	}
+	if newest.Author != "Gopher" || newest.Content != "Release v1.10.0" {
+		t.Errorf("author/content = %q / %q", newest.Author, newest.Content)
+	}
+	if newest.PublishedAt.Format("2006-01-02") != "2025-01-02" {
+		t.Errorf("PublishedAt = %v, want commit date", newest.PublishedAt)
+	}
+
+	// The oldest tag has nothing to compare against.
+	if oldest := articles[2]; oldest.URL != "https://github.com/owner/lib/releases/tag/v1.8.2" {
+		t.Errorf("oldest URL = %q", oldest.URL)
+	}
+
+	if len(cursor.Seen) != 3 || cursor.ETag != "" {
+		t.Errorf("cursor = %+v, want all tags seen and no ETag for a paged listing", cursor)
+	}
+}
+
+func TestTags_SinglePageKeepsETag(t *testing.T) {
+	var lookups []string
+	g := newTestGitHub(t, tagsAPI(t, []string{"v1.1.0", "v1.0.0"}, &lookups))
+
+	_, cursor, err := g.Tags(t.Context(), "owner/lib", store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cursor.ETag != `"tags-1"` {
+		t.Errorf("ETag = %q, want the only page's", cursor.ETag)
+	}
+}
+
+func TestTags_ReportsUnseenTagsOnAnyPage(t *testing.T) {
+	var lookups []string
+	// v1.9.1 is a backport listed on the last page.
+	g := newTestGitHub(t, tagsAPI(t, []string{"v1.9.0", "v1.10.0", "v1.8.2", "v1.9.1"}, &lookups))
+
+	cursor := store.Cursor{Seen: seenSet([]string{"v1.9.0", "v1.10.0", "v1.8.2"})}
+	articles, next, err := g.Tags(t.Context(), "owner/lib", cursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Version != "v1.9.1" {
+		t.Fatalf("articles = %+v, want only v1.9.1", articles)
+	}
+	if articles[0].URL != "https://github.com/owner/lib/compare/v1.9.0...v1.9.1" {
+		t.Errorf("URL = %q, want compare against the previous version", articles[0].URL)
+	}
+	if len(lookups) != 1 {
+		t.Errorf("commit lookups = %v, want only the new tag", lookups)
+	}
+	if _, ok := next.Seen["v1.9.1"]; !ok {
+		t.Errorf("Seen = %v, want the new tag recorded", next.Seen)
+	}
+}
+
+func TestTags_OnlyResolvesNewTags(t *testing.T) {
+	var lookups []string
+	g := newTestGitHub(t, tagsAPI(t, []string{"v1.11.0", "v1.10.0", "v1.9.0"}, &lookups))
+
+	articles, _, err := g.Tags(t.Context(), "owner/lib", store.Cursor{LatestID: "v1.10.0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Version != "v1.11.0" {
+		t.Fatalf("articles = %+v, want only v1.11.0", articles)
+	}
+	if len(lookups) != 1 {
+		t.Errorf("commit lookups = %v, want only the new tag", lookups)
+	}
+}
+
+func TestTags_CursorTagDeleted(t *testing.T) {
+	var lookups []string
+	g := newTestGitHub(t, tagsAPI(t, []string{"v2.1.0", "v2.0.0", "v1.0.0"}, &lookups))
+
+	// v2.0.5 was the newest tag last time but has since been deleted.
+	articles, _, err := g.Tags(t.Context(), "owner/lib", store.Cursor{LatestID: "v2.0.5"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Version != "v2.1.0" {
+		t.Errorf("articles = %+v, want only v2.1.0", articles)
+	}
+}
+
+func TestFeedSource(t *testing.T) {
+	tests := []struct {
+		url, source, target string
+	}{
+		{"https://go.dev/blog/feed.atom", model.SourceRSS, "https://go.dev/blog/feed.atom"},
+		{"github:tokio-rs/tokio", model.SourceGitHub, "tokio-rs/tokio"},
+		{"github-tags:owner/lib", model.SourceGitHubTags, "owner/lib"},
+	}
+	for _, tt := range tests {
+		f := model.Feed{URL: tt.url}
+		if f.Source() != tt.source || f.Target() != tt.target {
+			t.Errorf("%s: Source() = %q, Target() = %q; want %q, %q", tt.url, f.Source(), f.Target(), tt.source, tt.target)
+		}
+	}
+}
@@ -1,12 +1,46 @@
 package feed
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/google/go-github/v68/github"
+	"github.com/mayknxyz/my-feeder/internal/store"
 )
 
+// newTestGitHub returns a GitHub client pointed at a fake API server.
+func newTestGitHub(t *testing.T, handler http.Handler) *GitHub {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	g := NewGitHub("")
+	base, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.client.BaseURL = base
+	return g
+}
+
+// releasesJSON renders a release listing with IDs from hi down to lo.
+func releasesJSON(hi, lo int) string {
+	s := "["
+	for id := hi; id >= lo; id-- {
+		if id != hi {
+			s += ","
+		}
+		s += fmt.Sprintf(`{"id":%d,"tag_name":"v0.%d.0"}`, id, id)
+	}
+	return s + "]"
+}
+
 func TestMapRelease(t *testing.T) {
 	id := int64(12345)
 	name := "v1.0.0 — Initial Release"
@@ -32,9 +66,6 @@ func TestMapRelease(t *testing.T) {
 	if a.GUID != "github:owner/repo:12345" {
 		t.Errorf("GUID = %q", a.GUID)
 	}
-	if a.FeedURL != "github:owner/repo" {
-		t.Errorf("FeedURL = %q", a.FeedURL)
-	}
 	if a.Title != "v1.0.0 — Initial Release" {
 		t.Errorf("Title = %q", a.Title)
 	}
@@ -50,6 +81,9 @@ func TestMapRelease(t *testing.T) {
 	if !a.PublishedAt.Equal(pub) {
 		t.Errorf("PublishedAt = %v, want %v", a.PublishedAt, pub)
 	}
+	if a.Version != "v1.0.0" {
+		t.Errorf("Version = %q, want the tag", a.Version)
+	}
 }
 
 func TestMapRelease_NoName_FallsBackToTag(t *testing.T) {
@@ -78,6 +112,177 @@ func TestMapRelease_NoNameNoTag(t *testing.T) {
 	}
 }
 
+func TestReleases_FirstFetchReadsOnePage(t *testing.T) {
+	var requests int
+	g := newTestGitHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Link", `<http://example.invalid/?page=2>; rel="next"`)
+		fmt.Fprint(w, releasesJSON(30, 6))
+	}))
+
+	articles, cursor, err := g.Releases(t.Context(), "owner/repo", store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1 on first fetch", requests)
+	}
+	if len(articles) != 25 {
+		t.Errorf("articles = %d, want 25", len(articles))
+	}
+	if len(cursor.Seen) != 25 || cursor.ETag != `"v1"` {
+		t.Errorf("cursor = %+v, want 25 seen releases and etag", cursor)
+	}
+}
+
+func TestReleases_PagesUntilCursor(t *testing.T) {
+	g := newTestGitHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		switch page {
+		case 1:
+			w.Header().Set("Link", `<http://example.invalid/?page=2>; rel="next"`)
+			fmt.Fprint(w, releasesJSON(60, 36))
+		case 2:
+			w.Header().Set("Link", `<http://example.invalid/?page=3>; rel="next"`)
+			fmt.Fprint(w, releasesJSON(35, 11))
+		default:
+			t.Errorf("unexpected request for page %d", page)
+			fmt.Fprint(w, "[]")
+		}
+	}))
+
+	articles, cursor, err := g.Releases(t.Context(), "owner/repo", store.Cursor{LatestID: "20"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 60..21 are new: 25 from page one, 15 from page two.
+	if len(articles) != 40 {
+		t.Errorf("articles = %d, want 40", len(articles))
+	}
+	if _, ok := cursor.Seen["60"]; !ok || len(cursor.Seen) != 50 {
+		t.Errorf("cursor.Seen = %v, want the 50 releases read", cursor.Seen)
+	}
+}
+
+func TestReleases_DraftPublishedLater(t *testing.T) {
+	// Release 5 was drafted before 6 and 7 were published, and was
+	// itself published after the last refresh.
+	g := newTestGitHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":7,"tag_name":"v0.7.0"},{"id":6,"tag_name":"v0.6.0"},
+			{"id":5,"tag_name":"v0.6.1"},{"id":8,"tag_name":"v0.8.0","draft":true}]`)
+	}))
+
+	articles, cursor, err := g.Releases(t.Context(), "owner/repo", store.Cursor{Seen: map[string]string{"6": "", "7": ""}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Version != "v0.6.1" {
+		t.Errorf("articles = %+v, want only the published draft", articles)
+	}
+	if _, ok := cursor.Seen["8"]; ok {
+		t.Error("a draft was marked seen before it was published")
+	}
+}
+
+func TestReleases_NotModified(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	g := newTestGitHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != `"v1"` {
+			t.Errorf("If-None-Match = %q", r.Header.Get("If-None-Match"))
+		}
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "3")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusNotModified)
+	}))
+
+	prev := store.Cursor{ETag: `"v1"`, LatestID: "7"}
+	articles, cursor, err := g.Releases(t.Context(), "owner/repo", prev)
+	if err != nil {
+		t.Fatalf("304 should not be an error: %v", err)
+	}
+	if len(articles) != 0 {
+		t.Errorf("articles = %d, want 0", len(articles))
+	}
+	if !reflect.DeepEqual(cursor, prev) {
+		t.Errorf("cursor = %+v, want unchanged %+v", cursor, prev)
+	}
+
+	if rate := g.RateLimit(); rate.Remaining != 3 || rate.Limit != 60 {
+		t.Errorf("rate = %+v, want 3/60", rate)
+	}
+	if _, low := g.Exhausted(); !low {
+		t.Error("3 remaining should be below the reserve")
+	}
+}
+
+func TestReleases_SkipsDrafts(t *testing.T) {
+	g := newTestGitHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":2,"tag_name":"v2","draft":true},{"id":1,"tag_name":"v1"}]`)
+	}))
+
+	articles, _, err := g.Releases(t.Context(), "owner/repo", store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Title != "owner/repo v1" {
+		t.Errorf("articles = %+v, want only v1", articles)
+	}
+}
+
+func TestHasReleases(t *testing.T) {
+	g := newTestGitHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/tagged/releases" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"id":1,"tag_name":"v1"}]`)
+	}))
+
+	if ok, err := g.HasReleases(t.Context(), "owner/released"); err != nil || !ok {
+		t.Errorf("HasReleases(released) = %v, %v, want true", ok, err)
+	}
+	if ok, err := g.HasReleases(t.Context(), "owner/tagged"); err != nil || ok {
+		t.Errorf("HasReleases(tagged) = %v, %v, want false", ok, err)
+	}
+}
+
+func TestNewEnterpriseGitHub(t *testing.T) {
+	g, err := NewEnterpriseGitHub("token", "https://ghe.example.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := g.client.BaseURL.String(); got != "https://ghe.example.com/api/v3/" {
+		t.Errorf("REST base = %q", got)
+	}
+	if g.graphqlURL != "https://ghe.example.com/api/graphql" {
+		t.Errorf("GraphQL URL = %q", g.graphqlURL)
+	}
+	if got := g.webURL("team/app", "releases/tag/v1"); got != "https://ghe.example.com/team/app/releases/tag/v1" {
+		t.Errorf("web URL = %q", got)
+	}
+
+	// Instances served under a path prefix keep it in every URL.
+	g, err = NewEnterpriseGitHub("token", "https://corp.example.com/github")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := g.client.BaseURL.String(); got != "https://corp.example.com/github/api/v3/" {
+		t.Errorf("prefixed REST base = %q", got)
+	}
+	if g.graphqlURL != "https://corp.example.com/github/api/graphql" {
+		t.Errorf("prefixed GraphQL URL = %q", g.graphqlURL)
+	}
+	if got := g.webURL("team/app", "releases"); got != "https://corp.example.com/github/team/app/releases" {
+		t.Errorf("prefixed web URL = %q", got)
+	}
+
+	if _, err := NewEnterpriseGitHub("", "not a url"); err == nil {
+		t.Error("expected error for invalid base URL")
+	}
+}
+
 func TestTruncate(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -0,0 +1,239 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/release"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+// DefaultGoProxy is the module proxy used when neither the config nor
+// the GOPROXY environment variable names one.
+const DefaultGoProxy = "https://proxy.golang.org"
+
+// maxNewVersions caps how many .info lookups one refresh makes for a
+// module, so the first fetch of a long-lived module stays cheap.
+const maxNewVersions = 20
+
+// errProxyNotFound marks a 404/410 from a proxy, which the GOPROXY
+// list syntax treats differently from other failures.
+var errProxyNotFound = errors.New("not found")
+
+// GoProxy fetches module versions over the Go module proxy protocol.
+type GoProxy struct {
+	// URL is a GOPROXY value: a list of proxy URLs separated by ',' or
+	// '|'. file:// URLs read a directory laid out like a proxy.
+	URL    string
+	Client *http.Client
+}
+
+// goModInfo is the body of a /@v/<version>.info response.
+type goModInfo struct {
+	Version string    `json:"Version"`
+	Time    time.Time `json:"Time"`
+}
+
+// Versions lists a module's versions and returns one Article per version
+// missing from the cursor's Seen set.
+func (p *GoProxy) Versions(ctx context.Context, module string, cursor store.Cursor) ([]model.Article, store.Cursor, error) {
+	escaped, err := escapeModulePath(module)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	var articles []model.Article
+	next := cursor
+	err = p.eachProxy(func(base string) error {
+		next = cursor
+		list, err := p.get(ctx, base, escaped+"/@v/list")
+		if err != nil {
+			return err
+		}
+
+		listed := strings.Fields(string(list))
+		versions := newModuleVersions(listed, seenBefore(cursor))
+		next = store.Cursor{Seen: seenSet(listed)}
+
+		articles = articles[:0]
+		for _, v := range versions {
+			data, err := p.get(ctx, base, escaped+"/@v/"+v+".info")
+			if err != nil {
+				return err
+			}
+			var info goModInfo
+			if err := json.Unmarshal(data, &info); err != nil {
+				return fmt.Errorf("decoding %s@%s info: %w", module, v, err)
+			}
+			articles = append(articles, mapModuleVersion(module, v, info.Time))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, cursor, fmt.Errorf("fetching versions of %s: %w", module, err)
+	}
+	return articles, next, nil
+}
+
+// eachProxy calls fn with each proxy in the GOPROXY list until one
+// succeeds. As with the go command, a proxy followed by ',' only falls
+// through on "not found", while '|' falls through on any error.
+func (p *GoProxy) eachProxy(fn func(base string) error) error {
+	list := p.URL
+	if list == "" {
+		list = DefaultGoProxy
+	}
+
+	var lastErr error
+	for list != "" {
+		// LEARN: strings.IndexAny finds whichever separator comes first,
+		// so mixed lists like "a,b|c" split correctly.
+		entry, sep := list, byte(0)
+		if i := strings.IndexAny(list, ",|"); i >= 0 {
+			entry, sep, list = list[:i], list[i], list[i+1:]
+		} else {
+			list = ""
+		}
+		entry = strings.TrimSpace(entry)
+
+		switch entry {
+		case "", "direct":
+			// WHY: "direct" means fetching from version control, which
+			// feeder doesn't do — skip to the next proxy.
+			continue
+		case "off":
+			if lastErr == nil {
+				lastErr = fmt.Errorf("module proxy disabled (GOPROXY=off)")
+			}
+			return lastErr
+		}
+
+		lastErr = fn(strings.TrimSuffix(entry, "/"))
+		if lastErr == nil {
+			return nil
+		}
+		if sep != '|' && !errors.Is(lastErr, errProxyNotFound) {
+			return lastErr
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no usable module proxy in %q", p.URL)
+	}
+	return lastErr
+}
+
+// get reads path from a proxy base URL, which may be http(s) or file.
+func (p *GoProxy) get(ctx context.Context, base, path string) ([]byte, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module proxy %q: %w", base, err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		data, err := os.ReadFile(u.Path + "/" + path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%s/%s: %w", base, path, errProxyNotFound)
+		}
+		return data, err
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported module proxy scheme %q", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpx.UserAgent)
+	resp, err := httpx.Client(p.Client).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, fmt.Errorf("%s/%s: %w", base, path, errProxyNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s/%s: %s", base, path, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// newModuleVersions sorts a version list newest first and returns the
+// versions not seen before, capped at maxNewVersions.
+//
+// WHY: Comparing against the newest version seen would miss backports —
+// a v1.9.1 tagged after v1.10.0 is news too.
+func newModuleVersions(list []string, seen func(string) bool) []string {
+	type parsed struct {
+		raw string
+		v   release.Version
+	}
+	var versions []parsed
+	for _, raw := range list {
+		if v, ok := release.Parse(raw); ok {
+			versions = append(versions, parsed{raw, v})
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return release.Compare(versions[i].v, versions[j].v) > 0
+	})
+
+	var out []string
+	for _, v := range versions {
+		if seen(v.raw) {
+			continue
+		}
+		out = append(out, v.raw)
+		if len(out) == maxNewVersions {
+			break
+		}
+	}
+	return out
+}
+
+// mapModuleVersion converts a module version to an Article linking to
+// its pkg.go.dev page.
+func mapModuleVersion(module, version string, published time.Time) model.Article {
+	v, _ := release.Parse(version)
+	return releaseInfo{
+		guid:        fmt.Sprintf("gomod:%s@%s", module, version),
+		repo:        module,
+		tag:         version,
+		url:         fmt.Sprintf("https://pkg.go.dev/%s@%s", module, version),
+		prerelease:  v.IsPrerelease(),
+		publishedAt: published,
+	}.article()
+}
+
+// escapeModulePath applies the module proxy's case encoding: each
+// upper-case letter becomes '!' followed by its lower-case form, so
+// paths stay distinct on case-insensitive file systems.
+func escapeModulePath(module string) (string, error) {
+	if module == "" || strings.ContainsAny(module, "!@ ") || strings.HasPrefix(module, "/") {
+		return "", fmt.Errorf("invalid module path %q", module)
+	}
+	var b strings.Builder
+	for _, r := range module {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
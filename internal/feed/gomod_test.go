@@ -0,0 +1,139 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+// writeProxyDir lays out a directory-based module proxy for module with
+// the given version → RFC 3339 time pairs, and returns its file:// URL.
+func writeProxyDir(t *testing.T, escaped string, versions map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, filepath.FromSlash(escaped), "@v")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	list := ""
+	for v, ts := range versions {
+		list += v + "\n"
+		info := `{"Version":"` + v + `","Time":"` + ts + `"}`
+		if err := os.WriteFile(filepath.Join(dir, v+".info"), []byte(info), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "list"), []byte(list), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return "file://" + filepath.ToSlash(root)
+}
+
+func TestGoProxyVersions(t *testing.T) {
+	proxy := writeProxyDir(t, "example.com/!burnt!sushi/toml", map[string]string{
+		"v1.2.0":        "2024-01-10T00:00:00Z",
+		"v1.10.0":       "2024-06-01T00:00:00Z",
+		"v1.11.0-rc.1":  "2024-07-01T00:00:00Z",
+		"v1.9.0":        "2024-03-01T00:00:00Z",
+		"not-a-version": "2024-03-01T00:00:00Z",
+	})
+	p := &GoProxy{URL: proxy}
+
+	articles, cursor, err := p.Versions(t.Context(), "example.com/BurntSushi/toml", store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 4 {
+		t.Fatalf("got %d articles, want 4", len(articles))
+	}
+	first := articles[0]
+	if first.Version != "v1.11.0-rc.1" || !first.Prerelease {
+		t.Errorf("first = %s (prerelease %v), want v1.11.0-rc.1 prerelease", first.Version, first.Prerelease)
+	}
+	if first.GUID != "gomod:example.com/BurntSushi/toml@v1.11.0-rc.1" {
+		t.Errorf("GUID = %q", first.GUID)
+	}
+	if first.URL != "https://pkg.go.dev/example.com/BurntSushi/toml@v1.11.0-rc.1" {
+		t.Errorf("URL = %q", first.URL)
+	}
+	if got := first.PublishedAt.Format("2006-01-02"); got != "2024-07-01" {
+		t.Errorf("PublishedAt = %s, want 2024-07-01", got)
+	}
+	if len(cursor.Seen) != 5 {
+		t.Errorf("Seen = %v, want every listed version", cursor.Seen)
+	}
+
+	// A later refresh only reports versions it hasn't seen, including
+	// backports older than the newest one.
+	seen := store.Cursor{Seen: seenSet([]string{"v1.2.0", "v1.10.0", "v1.11.0-rc.1"})}
+	articles, _, err = p.Versions(t.Context(), "example.com/BurntSushi/toml", seen)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Version != "v1.9.0" {
+		t.Errorf("incremental articles = %+v, want v1.9.0", articles)
+	}
+
+	// Cursors from before Seen was kept count versions up to LatestID
+	// as seen.
+	articles, _, err = p.Versions(t.Context(), "example.com/BurntSushi/toml", store.Cursor{LatestID: "v1.9.0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 2 || articles[1].Version != "v1.10.0" {
+		t.Errorf("legacy cursor articles = %+v, want v1.11.0-rc.1 and v1.10.0", articles)
+	}
+}
+
+func TestGoProxyVersions_FallsThroughOnNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	local := writeProxyDir(t, "example.com/mod", map[string]string{"v0.1.0": "2024-01-01T00:00:00Z"})
+
+	p := &GoProxy{URL: srv.URL + ",direct," + local}
+	articles, _, err := p.Versions(t.Context(), "example.com/mod", store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 {
+		t.Errorf("got %d articles, want 1 from the second proxy", len(articles))
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	p = &GoProxy{URL: failing.URL + "," + local}
+	if _, _, err := p.Versions(t.Context(), "example.com/mod", store.Cursor{}); err == nil {
+		t.Error("',' should not fall through on a server error")
+	}
+	p = &GoProxy{URL: failing.URL + "|" + local}
+	if _, _, err := p.Versions(t.Context(), "example.com/mod", store.Cursor{}); err != nil {
+		t.Errorf("'|' should fall through on any error, got %v", err)
+	}
+}
+
+func TestEscapeModulePath(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"golang.org/x/mod", "golang.org/x/mod"},
+		{"github.com/BurntSushi/toml", "github.com/!burnt!sushi/toml"},
+	}
+	for _, tt := range tests {
+		got, err := escapeModulePath(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("escapeModulePath(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+	if _, err := escapeModulePath("bad!path"); err == nil {
+		t.Error("expected error for path containing '!'")
+	}
+}
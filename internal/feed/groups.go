@@ -0,0 +1,155 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/go-github/v68/github"
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+const (
+	// groupRefreshInterval is how long a group's cached member list is
+	// trusted before it's fetched again. Stars change rarely; releases
+	// are what we refresh often.
+	groupRefreshInterval = 24 * time.Hour
+
+	// starsPerPage and maxStarPages bound how many starred repos one
+	// group can expand to.
+	starsPerPage = 100
+	maxStarPages = 10
+
+	// defaultStarsTag is the tag given to expanded feeds when the group
+	// itself has none.
+	defaultStarsTag = "stars"
+)
+
+// Starred lists the repos ("owner/repo") a GitHub user has starred,
+// most recently starred first.
+func (g *GitHub) Starred(ctx context.Context, user string) ([]string, error) {
+	if user == "" {
+		return nil, fmt.Errorf("empty GitHub username")
+	}
+
+	opts := &github.ActivityListStarredOptions{
+		Sort:        "created",
+		ListOptions: github.ListOptions{PerPage: starsPerPage},
+	}
+	var repos []string
+	for page := 1; ; page++ {
+		starred, resp, err := g.client.Activity.ListStarred(ctx, user, opts)
+		g.recordRate(resp)
+		if err != nil {
+			return nil, fmt.Errorf("listing stars of %s: %w", user, err)
+		}
+		for _, s := range starred {
+			repos = append(repos, s.GetRepository().GetFullName())
+		}
+		if resp.NextPage == 0 || page >= maxStarPages {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return repos, nil
+}
+
+// expandGroups replaces each feed group with the feeds it stands for.
+// Groups whose members can't be determined are returned as failed
+// results instead.
+func (f *Fetcher) expandGroups(ctx context.Context) ([]model.Feed, []FetchResult) {
+	// Repos that already have their own feed aren't added twice, whether
+	// it follows releases or tags. GitHub repo names ignore case.
+	configured := make(map[string]bool)
+	for _, fd := range f.Feeds {
+		switch fd.Source() {
+		case model.SourceGitHub, model.SourceGitHubTags:
+			configured[strings.ToLower(fd.Target())] = true
+		}
+	}
+
+	var feeds []model.Feed
+	var failed []FetchResult
+	for _, fd := range f.Feeds {
+		if !fd.IsGroup() {
+			feeds = append(feeds, fd)
+			continue
+		}
+
+		members, err := f.groupMembers(ctx, fd)
+		if err != nil {
+			failed = append(failed, FetchResult{Feed: fd, Err: err})
+			continue
+		}
+		for _, repo := range members {
+			key := strings.ToLower(repo)
+			if configured[key] || excluded(fd.Exclude, repo) {
+				continue
+			}
+			configured[key] = true
+			feeds = append(feeds, starFeed(fd, repo))
+		}
+	}
+	return feeds, failed
+}
+
+// groupMembers returns a group's members, re-fetching them when the
+// cached list is older than groupRefreshInterval. A stale list is used
+// if the refresh fails, so one API hiccup doesn't drop every feed.
+func (f *Fetcher) groupMembers(ctx context.Context, group model.Feed) ([]string, error) {
+	f.mu.Lock()
+	cached, ok := f.Cache.Groups[group.ID]
+	f.mu.Unlock()
+	if ok && time.Since(cached.FetchedAt) < groupRefreshInterval {
+		return cached.Members, nil
+	}
+
+	err := f.githubQuota()
+	var members []string
+	if err == nil {
+		members, err = f.GitHub.Starred(ctx, group.Target())
+	}
+	if err != nil {
+		if ok {
+			log.Warn("Using cached group members", "feed", group.Name, "error", err)
+			return cached.Members, nil
+		}
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.Cache.Groups[group.ID] = store.Group{Members: members, FetchedAt: time.Now().UTC()}
+	f.mu.Unlock()
+	log.Info("Group expanded", "feed", group.Name, "members", len(members))
+	return members, nil
+}
+
+// excluded reports whether repo matches any of the exclude patterns.
+func excluded(patterns []string, repo string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, repo); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// starFeed builds the release feed a group expands to for one repo.
+// It inherits the group's retention and release filters.
+func starFeed(group model.Feed, repo string) model.Feed {
+	fd := group
+	// WHY: Feed IDs can't contain '/'. GitHub owner names can't contain
+	// '_', so the first one marks where the owner ends.
+	fd.ID = group.ID + "-" + strings.Replace(repo, "/", "_", 1)
+	fd.Name = repo
+	fd.URL = "github:" + repo
+	fd.Exclude = nil
+	if fd.Tag == "" {
+		fd.Tag = defaultStarsTag
+	}
+	return fd
+}
@@ -0,0 +1,102 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+// starsAPI fakes the starred-repos listing for user "gopher".
+func starsAPI(t *testing.T, repos []string, requests *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if r.URL.Path != "/users/gopher/starred" {
+			t.Errorf("path = %q", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		var items []string
+		for _, repo := range repos {
+			items = append(items, fmt.Sprintf(`{"repo":{"full_name":%q}}`, repo))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(items, ","))
+	})
+}
+
+func TestExpandGroups(t *testing.T) {
+	var requests int
+	g := newTestGitHub(t, starsAPI(t, []string{"tokio-rs/tokio", "golang/go", "burntsushi/ripgrep", "me/dotfiles", "me/site"}, &requests))
+
+	no := false
+	f := &Fetcher{
+		Feeds: []model.Feed{
+			{ID: "blog", Name: "Blog", URL: "https://example.com/feed.xml"},
+			{ID: "go", Name: "Go", URL: "github:golang/go"},
+			{ID: "rg", Name: "ripgrep", URL: "github-tags:BurntSushi/ripgrep"},
+			{ID: "stars", Name: "My stars", URL: "github-stars:gopher", Exclude: []string{"me/*"}, Prereleases: &no},
+		},
+		Cache:  store.NewCache(),
+		GitHub: g,
+	}
+
+	feeds, failed := f.expandGroups(t.Context())
+	if len(failed) != 0 {
+		t.Fatalf("failed = %+v", failed)
+	}
+
+	var urls []string
+	for _, fd := range feeds {
+		urls = append(urls, fd.URL)
+	}
+	want := "https://example.com/feed.xml github:golang/go github-tags:BurntSushi/ripgrep github:tokio-rs/tokio"
+	if got := strings.Join(urls, " "); got != want {
+		t.Errorf("expanded feeds = %s, want %s", got, want)
+	}
+
+	tokio := feeds[3]
+	if tokio.ID != "stars-tokio-rs_tokio" || tokio.Name != "tokio-rs/tokio" || tokio.Tag != "stars" {
+		t.Errorf("star feed = %+v", tokio)
+	}
+	if tokio.Prereleases == nil || *tokio.Prereleases {
+		t.Error("star feed should inherit the group's release filters")
+	}
+
+	// A second expansion within the refresh interval uses the cache.
+	if _, _ = f.expandGroups(t.Context()); requests != 1 {
+		t.Errorf("requests = %d, want 1 — member list should be cached", requests)
+	}
+}
+
+func TestExpandGroups_StaleCacheOnError(t *testing.T) {
+	g := newTestGitHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Server Error"}`, http.StatusInternalServerError)
+	}))
+
+	cache := store.NewCache()
+	cache.Groups["stars"] = store.Group{
+		Members:   []string{"tokio-rs/tokio"},
+		FetchedAt: time.Now().Add(-48 * time.Hour),
+	}
+	f := &Fetcher{
+		Feeds:  []model.Feed{{ID: "stars", Name: "My stars", URL: "github-stars:gopher"}},
+		Cache:  cache,
+		GitHub: g,
+	}
+
+	feeds, failed := f.expandGroups(t.Context())
+	if len(failed) != 0 || len(feeds) != 1 || feeds[0].URL != "github:tokio-rs/tokio" {
+		t.Errorf("feeds = %+v, failed = %+v; want stale member list", feeds, failed)
+	}
+
+	// With nothing cached, the group itself fails.
+	f.Cache = store.NewCache()
+	feeds, failed = f.expandGroups(t.Context())
+	if len(feeds) != 0 || len(failed) != 1 || failed[0].Err == nil {
+		t.Errorf("feeds = %+v, failed = %+v; want one failed group", feeds, failed)
+	}
+}
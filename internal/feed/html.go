@@ -0,0 +1,84 @@
+package feed
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// skippedElements hold no readable text: metadata, scripts and styles.
+var skippedElements = map[string]bool{
+	"head": true, "script": true, "style": true, "template": true, "noscript": true,
+}
+
+// htmlText flattens an HTML fragment to plain text: tags are dropped,
+// entities decoded, and block elements become line breaks.
+func htmlText(s string) string {
+	var b strings.Builder
+	skip := 0
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				return strings.TrimSpace(b.String())
+			}
+			return strings.TrimSpace(collapseBlankLines(b.String()))
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(z.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			tt := z.Token()
+			if skippedElements[tt.Data] {
+				switch tt.Type {
+				case html.StartTagToken:
+					skip++
+				case html.EndTagToken:
+					skip = max(skip-1, 0)
+				}
+				continue
+			}
+			switch tt.Data {
+			case "br":
+				b.WriteByte('\n')
+			case "td", "th":
+				b.WriteByte(' ')
+			case "p", "div", "li", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "pre", "tr",
+				"section", "article", "header", "footer", "nav", "ul", "ol", "table", "dt", "dd", "hr":
+				b.WriteString("\n\n")
+			}
+		}
+	}
+}
+
+// collapseBlankLines reduces runs of blank lines to a single one.
+func collapseBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// firstLine returns the first non-empty line of s, truncated to maxLen.
+func firstLine(s string, maxLen int) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return truncate(line, maxLen)
+		}
+	}
+	return ""
+}
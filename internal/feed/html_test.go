@@ -0,0 +1,26 @@
+package feed
+
+import "testing"
+
+func TestHTMLText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"<p>Hello <b>world</b> &amp; friends</p>", "Hello world & friends"},
+		{"<p>One</p><p>Two<br>Three</p>", "One\n\nTwo\nThree"},
+		{"<head><title>T</title><style>p{}</style></head><body><script>x()</script><p>Body</p></body>", "Body"},
+		{"plain text", "plain text"},
+	}
+	for _, tt := range tests {
+		if got := htmlText(tt.input); got != tt.want {
+			t.Errorf("htmlText(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate_RuneBoundary(t *testing.T) {
+	if got := truncate("héllo wörld", 8); got != "héll..." {
+		t.Errorf("truncate = %q, want %q", got, "héll...")
+	}
+}
@@ -0,0 +1,568 @@
+package feed
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// defaultLookaheadDays is how far ahead recurring events are expanded
+// when a feed doesn't set lookahead_days.
+const defaultLookaheadDays = 90
+
+// Calendar turns iCalendar (ICS) feeds into one article per upcoming
+// event, dated by when it starts.
+type Calendar struct {
+	Client *http.Client
+}
+
+// Events fetches an ics: feed and returns the events that haven't ended
+// yet. Recurring events are expanded up to the feed's lookahead.
+func (c *Calendar) Events(ctx context.Context, feed model.Feed) ([]model.Article, error) {
+	calURL := feed.Target()
+	// WHY: Calendar apps publish webcal:// links; it's plain HTTPS.
+	if rest, ok := strings.CutPrefix(calURL, "webcal://"); ok {
+		calURL = "https://" + rest
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, calURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request for %s: %w", calURL, err)
+	}
+	req.Header.Set("User-Agent", httpx.UserAgent)
+	resp, err := httpx.Client(c.Client).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", calURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", calURL, resp.Status)
+	}
+
+	events, err := parseICS(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", calURL, err)
+	}
+
+	days := defaultLookaheadDays
+	if feed.ICS != nil && feed.ICS.LookaheadDays > 0 {
+		days = feed.ICS.LookaheadDays
+	}
+	now := time.Now()
+	return calendarArticles(calURL, events, now, now.AddDate(0, 0, days)), nil
+}
+
+// icsEvent is one VEVENT. For recurring events it describes the series;
+// an event with a recurrenceID overrides one occurrence of its series.
+type icsEvent struct {
+	uid, summary, description, location, url, status string
+
+	start, end time.Time
+	allDay     bool
+	duration   icsDuration
+
+	rrule        string
+	rdates       []time.Time
+	exdates      []time.Time
+	recurrenceID time.Time
+}
+
+// recurring reports whether the event describes a series.
+func (ev icsEvent) recurring() bool {
+	return ev.rrule != "" || len(ev.rdates) > 0
+}
+
+// icsDuration is a DURATION value. Days are kept apart from the clock
+// part because a day is a calendar day, not 24 hours, across DST.
+type icsDuration struct {
+	days  int
+	clock time.Duration
+}
+
+// parseICS reads the VEVENTs of an iCalendar document. Other components
+// (VTODO, VTIMEZONE, VALARM inside events) are skipped; TZID parameters
+// are resolved against the system's zone database instead. Unreadable
+// properties are logged and ignored, and an event whose start can't be
+// read is skipped; only a document that can't be read at all is an
+// error.
+func parseICS(r io.Reader) ([]icsEvent, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events []icsEvent
+		ev     *icsEvent
+		depth  int   // components nested in the current VEVENT, e.g. VALARM
+		bad    error // why the current VEVENT can't be placed, if it can't
+	)
+	for _, line := range lines {
+		name, params, value, ok := parseContentLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "BEGIN" && ev != nil:
+			depth++
+			continue
+		case name == "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				ev, bad = &icsEvent{}, nil
+			}
+			continue
+		case name == "END" && ev != nil && depth > 0:
+			depth--
+			continue
+		case name == "END" && ev != nil:
+			switch {
+			case bad != nil:
+				log.Warn("Skipping unreadable calendar event", "event", cmp.Or(ev.summary, ev.uid), "error", bad)
+			case !ev.start.IsZero():
+				events = append(events, ev.finish())
+			}
+			ev = nil
+			continue
+		case ev == nil || depth > 0:
+			continue
+		}
+
+		if err := ev.set(name, params, value); err != nil {
+			// WHY: Like an odd RRULE, one bad property shouldn't hide the
+			// rest of the calendar. Only an event that can't be placed in
+			// time is dropped; other properties are just left out.
+			if name == "DTSTART" || name == "RECURRENCE-ID" {
+				bad = fmt.Errorf("%s: %w", name, err)
+				continue
+			}
+			log.Warn("Ignoring unreadable calendar property", "event", cmp.Or(ev.summary, ev.uid), "property", name, "error", err)
+		}
+	}
+	return events, nil
+}
+
+// set applies one property to the event. Unknown properties are ignored.
+func (ev *icsEvent) set(name string, params map[string]string, value string) error {
+	switch name {
+	case "UID":
+		ev.uid = value
+	case "SUMMARY":
+		ev.summary = unescapeICS(value)
+	case "DESCRIPTION":
+		ev.description = unescapeICS(value)
+	case "LOCATION":
+		ev.location = unescapeICS(value)
+	case "URL":
+		ev.url = value
+	case "STATUS":
+		ev.status = strings.ToUpper(value)
+	case "DTSTART":
+		t, allDay, err := parseICSTime(value, params, nil)
+		if err != nil {
+			return err
+		}
+		ev.start, ev.allDay = t, allDay
+	case "DTEND":
+		t, _, err := parseICSTime(value, params, nil)
+		if err != nil {
+			return err
+		}
+		ev.end = t
+	case "DURATION":
+		d, err := parseICSDuration(value)
+		if err != nil {
+			return err
+		}
+		ev.duration = d
+	case "RRULE":
+		ev.rrule = value
+	case "RDATE", "EXDATE":
+		for _, v := range strings.Split(value, ",") {
+			// A VALUE=PERIOD RDATE is "start/end" or "start/duration";
+			// the occurrence still runs as long as the event does.
+			v, _, _ = strings.Cut(v, "/")
+			t, _, err := parseICSTime(v, params, nil)
+			if err != nil {
+				return err
+			}
+			if name == "RDATE" {
+				ev.rdates = append(ev.rdates, t)
+			} else {
+				ev.exdates = append(ev.exdates, t)
+			}
+		}
+	case "RECURRENCE-ID":
+		t, _, err := parseICSTime(value, params, nil)
+		if err != nil {
+			return err
+		}
+		ev.recurrenceID = t
+	}
+	return nil
+}
+
+// finish fills in the end of an event that gave a DURATION or nothing.
+// It runs after all properties are read, since RFC 5545 doesn't order
+// DTSTART before DURATION.
+func (ev *icsEvent) finish() icsEvent {
+	switch {
+	case !ev.end.IsZero():
+	case ev.duration != icsDuration{}:
+		ev.end = ev.start.AddDate(0, 0, ev.duration.days).Add(ev.duration.clock)
+	case ev.allDay:
+		ev.end = ev.start.AddDate(0, 0, 1)
+	default:
+		ev.end = ev.start
+	}
+	return *ev
+}
+
+// unfoldICS splits a document into logical lines, joining the
+// continuation lines that start with a space or tab.
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	// LEARN: Scanner's default 64 KiB line limit is easy to hit with
+	// long descriptions; give it room to grow.
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+// parseContentLine splits "NAME;PARAM=x;P2=\"a:b\":value". Names and
+// parameter names are upper-cased; quoted parameter values may contain
+// ':' and ';'.
+func parseContentLine(line string) (name string, params map[string]string, value string, ok bool) {
+	inQuote := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		} else if r == ':' && !inQuote {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := splitUnquoted(head, ';')
+	name = strings.ToUpper(parts[0])
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return name, params, value, true
+}
+
+// splitUnquoted splits s on sep outside double quotes.
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	inQuote := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == sep && !inQuote:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescapeICS decodes TEXT escapes: \n, \, \; and \\.
+func unescapeICS(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// parseICSTime parses a DATE or DATE-TIME value. UTC times end in "Z";
+// a TZID parameter names the zone; anything else is floating and read
+// in fallback, or local time if fallback is nil. Zones the system
+// doesn't know (often Windows names from Outlook) fall back too.
+func parseICSTime(value string, params map[string]string, fallback *time.Location) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	loc := fallback
+	if loc == nil {
+		loc = time.Local
+	}
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseICSDuration parses a DURATION like "PT1H30M", "P2D" or "P1W".
+func parseICSDuration(s string) (icsDuration, error) {
+	var d icsDuration
+	orig := s
+	sign := 1
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		sign, s = -1, rest
+	}
+	s = strings.TrimPrefix(s, "+")
+	s, ok := strings.CutPrefix(s, "P")
+	if !ok || s == "" {
+		return d, fmt.Errorf("duration %q", orig)
+	}
+
+	inTime := false
+	num := ""
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return d, fmt.Errorf("duration %q", orig)
+		}
+		num = ""
+		switch {
+		case r == 'W' && !inTime:
+			d.days += 7 * n
+		case r == 'D' && !inTime:
+			d.days += n
+		case r == 'H' && inTime:
+			d.clock += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			d.clock += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			d.clock += time.Duration(n) * time.Second
+		default:
+			return d, fmt.Errorf("duration %q", orig)
+		}
+	}
+	if num != "" {
+		return d, fmt.Errorf("duration %q", orig)
+	}
+	d.days *= sign
+	d.clock *= time.Duration(sign)
+	return d, nil
+}
+
+// eventOccurrence is one dated instance of an event.
+type eventOccurrence struct {
+	event      icsEvent
+	start, end time.Time
+	recurring  bool
+}
+
+// expandEvents returns the occurrences of events that are still running
+// at now. Recurring series are expanded up to horizon, with EXDATEs
+// removed and RECURRENCE-ID overrides swapped in; one-off events are
+// kept however far ahead they are. Cancelled events are left out.
+func expandEvents(events []icsEvent, now, horizon time.Time) []eventOccurrence {
+	// Overrides replace single occurrences of a series, keyed by UID and
+	// the start they replace.
+	overrides := make(map[string]icsEvent)
+	recurringUIDs := make(map[string]bool)
+	for _, ev := range events {
+		if !ev.recurrenceID.IsZero() {
+			overrides[occurrenceKey(ev.uid, ev.recurrenceID)] = ev
+		}
+		if ev.recurring() {
+			recurringUIDs[ev.uid] = true
+		}
+	}
+
+	var out []eventOccurrence
+	keep := func(o eventOccurrence) {
+		if o.event.status == "CANCELLED" || !o.end.After(now) {
+			return
+		}
+		if o.recurring && o.start.After(horizon) {
+			return
+		}
+		out = append(out, o)
+	}
+
+	for _, ev := range events {
+		if !ev.recurrenceID.IsZero() {
+			keep(eventOccurrence{event: ev, start: ev.start, end: ev.end, recurring: recurringUIDs[ev.uid]})
+			continue
+		}
+		if !ev.recurring() {
+			keep(eventOccurrence{event: ev, start: ev.start, end: ev.end})
+			continue
+		}
+
+		starts := []time.Time{ev.start}
+		if ev.rrule != "" {
+			rule, err := parseRRule(ev.rrule, ev.start.Location())
+			if err != nil {
+				// WHY: One odd rule shouldn't hide the rest of the
+				// calendar. Showing the first occurrence is still better
+				// than dropping the event.
+				msg := "Bad recurrence rule, showing first occurrence only"
+				if errors.Is(err, errUnsupportedRule) {
+					msg = "Recurrence rule not supported, showing first occurrence only"
+				}
+				log.Warn(msg, "event", ev.summary, "error", err)
+			} else {
+				starts = rule.occurrences(ev.start, horizon)
+			}
+		}
+		starts = append(starts, ev.rdates...)
+		length := ev.end.Sub(ev.start)
+
+		seen := make(map[int64]bool)
+		for _, start := range starts {
+			if seen[start.Unix()] || slices.ContainsFunc(ev.exdates, start.Equal) {
+				continue
+			}
+			seen[start.Unix()] = true
+			if _, ok := overrides[occurrenceKey(ev.uid, start)]; ok {
+				continue
+			}
+			keep(eventOccurrence{event: ev, start: start, end: start.Add(length), recurring: true})
+		}
+	}
+
+	slices.SortStableFunc(out, func(a, b eventOccurrence) int { return a.start.Compare(b.start) })
+	return out
+}
+
+// occurrenceKey identifies one occurrence of a series.
+func occurrenceKey(uid string, start time.Time) string {
+	return uid + "|" + strconv.FormatInt(start.Unix(), 10)
+}
+
+// calendarArticles maps the occurrences of events that are still to
+// come, or under way, at now.
+func calendarArticles(calURL string, events []icsEvent, now, horizon time.Time) []model.Article {
+	occurrences := expandEvents(events, now, horizon)
+	articles := make([]model.Article, 0, len(occurrences))
+	for _, o := range occurrences {
+		articles = append(articles, mapOccurrence(calURL, o))
+	}
+	return articles
+}
+
+// mapOccurrence converts one event occurrence into an article. The
+// article is dated by the event's start, and EndsAt makes retention
+// count from when it's over.
+func mapOccurrence(calURL string, o eventOccurrence) model.Article {
+	ev := o.event
+	uid := ev.uid
+	if uid == "" {
+		h := sha256.Sum256([]byte(calURL + "|" + ev.summary))
+		uid = fmt.Sprintf("sha256:%x", h[:8])
+	}
+	title := ev.summary
+	if title == "" {
+		title = "Untitled event"
+	}
+
+	when := formatEventTime(o.start, o.end, ev.allDay)
+	summary := when
+	if ev.location != "" {
+		summary += " · " + ev.location
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**When:** %s\n", when)
+	if ev.location != "" {
+		fmt.Fprintf(&b, "**Where:** %s\n", ev.location)
+	}
+	if ev.description != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(ev.description))
+	}
+	if ev.url != "" {
+		fmt.Fprintf(&b, "\n[Event page](%s)\n", ev.url)
+	}
+
+	a := model.Article{
+		// WHY: Keying on the start as well as the UID gives each
+		// occurrence its own article, and makes a rescheduled event show
+		// up again at its new time.
+		GUID:        fmt.Sprintf("ics:%s@%s", uid, o.start.UTC().Format("20060102T150405Z")),
+		Title:       title,
+		URL:         ev.url,
+		Summary:     summary,
+		Content:     b.String(),
+		PublishedAt: o.start,
+		EndsAt:      o.end,
+		FetchedAt:   time.Now(),
+	}
+	return a
+}
+
+// formatEventTime renders when an event happens, in the event's own
+// time zone.
+func formatEventTime(start, end time.Time, allDay bool) string {
+	const day = "Mon 2 Jan 2006"
+	if allDay {
+		last := end.AddDate(0, 0, -1) // DTEND of an all-day event is exclusive
+		if !last.After(start) {
+			return start.Format(day)
+		}
+		return start.Format(day) + " – " + last.Format(day)
+	}
+	if !end.After(start) {
+		return start.Format(day + ", 15:04 MST")
+	}
+	if start.Format("20060102") == end.Format("20060102") {
+		return start.Format(day+", 15:04") + " – " + end.Format("15:04 MST")
+	}
+	return start.Format(day+", 15:04") + " – " + end.Format(day+", 15:04 MST")
+}
@@ -0,0 +1,182 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+func TestCalendarArticles(t *testing.T) {
+	f, err := os.Open("testdata/meetups.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	events, err := parseICS(f)
+	if err != nil {
+		t.Fatalf("parseICS: %v", err)
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no zone data: %v", err)
+	}
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	articles := calendarArticles("https://example.com/cal.ics", events, now, now.AddDate(0, 0, 30))
+
+	tests := []struct {
+		title string
+		start time.Time
+		end   time.Time
+		url   string
+	}{
+		// The override replaces the 12 March occurrence; 19 March is an
+		// EXDATE, and COUNT=8 ends the series after 26 March.
+		{"Go meetup (moved to Wednesday)", time.Date(2024, 3, 13, 19, 0, 0, 0, berlin), time.Date(2024, 3, 13, 21, 0, 0, 0, berlin), ""},
+		{"Go meetup", time.Date(2024, 3, 26, 18, 30, 0, 0, berlin), time.Date(2024, 3, 26, 20, 30, 0, 0, berlin), "https://meetup.example.com/go"},
+		{"End-of-month drinks", time.Date(2024, 3, 29, 17, 0, 0, 0, time.UTC), time.Date(2024, 3, 29, 20, 0, 0, 0, time.UTC), ""},
+		// One-off events are kept past the lookahead.
+		{"GopherCon EU", time.Date(2024, 6, 17, 0, 0, 0, 0, time.Local), time.Date(2024, 6, 21, 0, 0, 0, 0, time.Local), "https://gophercon.eu/"},
+	}
+	if len(articles) != len(tests) {
+		for _, a := range articles {
+			t.Logf("got %q at %v", a.Title, a.PublishedAt)
+		}
+		t.Fatalf("got %d articles, want %d", len(articles), len(tests))
+	}
+	guids := make(map[string]bool)
+	for i, tt := range tests {
+		a := articles[i]
+		if a.Title != tt.title {
+			t.Errorf("articles[%d].Title = %q, want %q", i, a.Title, tt.title)
+		}
+		if !a.PublishedAt.Equal(tt.start) {
+			t.Errorf("articles[%d].PublishedAt = %v, want %v", i, a.PublishedAt, tt.start)
+		}
+		if !a.EndsAt.Equal(tt.end) {
+			t.Errorf("articles[%d].EndsAt = %v, want %v", i, a.EndsAt, tt.end)
+		}
+		if a.URL != tt.url {
+			t.Errorf("articles[%d].URL = %q, want %q", i, a.URL, tt.url)
+		}
+		if isDuplicateFrom(model.SourceICS, a, articles[:i], 30) {
+			t.Errorf("articles[%d] is deduped against an earlier occurrence", i)
+		}
+		if guids[a.GUID] {
+			t.Errorf("articles[%d].GUID %q is not unique", i, a.GUID)
+		}
+		guids[a.GUID] = true
+	}
+
+	conf := articles[3]
+	for _, want := range []string{
+		"**When:** Mon 17 Jun 2024 – Thu 20 Jun 2024",
+		"**Where:** Festsaal Kreuzberg, Berlin",
+		"CFP closes in April; tickets on sale now.",
+		"[Event page](https://gophercon.eu/)",
+	} {
+		if !strings.Contains(conf.Content, want) {
+			t.Errorf("Content missing %q:\n%s", want, conf.Content)
+		}
+	}
+	// Recurring events keep their link in the content.
+	if !strings.Contains(articles[1].Content, "[Event page](https://meetup.example.com/go)") {
+		t.Errorf("recurring event content lacks its link:\n%s", articles[1].Content)
+	}
+	if want := "Tue 26 Mar 2024, 18:30 – 20:30 CET · Room 4"; articles[1].Summary != want {
+		t.Errorf("Summary = %q, want %q", articles[1].Summary, want)
+	}
+}
+
+func TestParseICS_SkipsUnreadableEvents(t *testing.T) {
+	doc := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT", "UID:good", "SUMMARY:Standup", "DTSTART:20240311T090000Z", "DURATION:soon",
+		"RDATE;VALUE=PERIOD:20240318T090000Z/PT1H", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:broken", "SUMMARY:Mystery", "DTSTART:next tuesday", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:also-good", "SUMMARY:Retro", "DTSTART;VALUE=DATE:20240315", "END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := parseICS(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("parseICS: %v", err)
+	}
+	if len(events) != 2 || events[0].uid != "good" || events[1].uid != "also-good" {
+		t.Fatalf("got %+v, want the good and also-good events", events)
+	}
+	// The bad DURATION is dropped; the period RDATE is kept by its start.
+	if !events[0].end.Equal(events[0].start) {
+		t.Errorf("end = %v, want the start with no readable duration", events[0].end)
+	}
+	if want := time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC); len(events[0].rdates) != 1 || !events[0].rdates[0].Equal(want) {
+		t.Errorf("rdates = %v, want [%v]", events[0].rdates, want)
+	}
+}
+
+func TestCalendarEvents_Webcal(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now().Add(48 * time.Hour).UTC().Format("20060102T150405Z")
+		w.Header().Set("Content-Type", "text/calendar")
+		w.Write([]byte("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Launch\r\nDTSTART:" + start + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+	}))
+	defer srv.Close()
+
+	c := &Calendar{Client: srv.Client()}
+	articles, err := c.Events(t.Context(), model.Feed{
+		Name: "Launches",
+		URL:  "ics:webcal://" + strings.TrimPrefix(srv.URL, "https://") + "/cal.ics",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Title != "Launch" {
+		t.Errorf("got %+v, want one Launch event", articles)
+	}
+}
+
+func TestParseContentLine(t *testing.T) {
+	name, params, value, ok := parseContentLine(`DTSTART;TZID="America/New York:ish";VALUE=DATE-TIME:20240101T090000`)
+	if !ok {
+		t.Fatal("not parsed")
+	}
+	if name != "DTSTART" || value != "20240101T090000" {
+		t.Errorf("name, value = %q, %q", name, value)
+	}
+	if params["TZID"] != "America/New York:ish" || params["VALUE"] != "DATE-TIME" {
+		t.Errorf("params = %v", params)
+	}
+}
+
+func TestParseICSDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want icsDuration
+	}{
+		{"PT1H30M", icsDuration{clock: 90 * time.Minute}},
+		{"P2D", icsDuration{days: 2}},
+		{"P1W", icsDuration{days: 7}},
+		{"P1DT12H", icsDuration{days: 1, clock: 12 * time.Hour}},
+		{"-PT15M", icsDuration{clock: -15 * time.Minute}},
+	}
+	for _, tt := range tests {
+		got, err := parseICSDuration(tt.in)
+		if err != nil {
+			t.Errorf("parseICSDuration(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseICSDuration(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "P", "PT1D", "P1H", "P1"} {
+		if _, err := parseICSDuration(bad); err == nil {
+			t.Errorf("parseICSDuration(%q) succeeded, want error", bad)
+		}
+	}
+}
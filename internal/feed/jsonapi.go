@@ -0,0 +1,205 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/jsonpath"
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// JSONAPI turns arbitrary JSON endpoints into articles, using the field
+// mapping in a feed's [feeds.json] block.
+type JSONAPI struct {
+	Client *http.Client
+}
+
+// Items fetches a json: feed's endpoint and maps each item to an
+// article.
+func (j *JSONAPI) Items(ctx context.Context, feed model.Feed) ([]model.Article, error) {
+	endpoint := feed.Target()
+	if feed.JSON == nil {
+		return nil, fmt.Errorf("json feed %q has no [feeds.json] block", feed.Name)
+	}
+
+	header := make(http.Header)
+	for k, v := range feed.JSON.Headers {
+		// WHY: Expanding here keeps tokens for internal APIs in the
+		// environment instead of in a config file that gets synced.
+		header.Set(k, os.ExpandEnv(v))
+	}
+
+	var raw json.RawMessage
+	if err := httpx.GetJSON(ctx, j.Client, endpoint, header, &raw); err != nil {
+		return nil, err
+	}
+
+	// LEARN: UseNumber keeps numbers as json.Number strings instead of
+	// float64, so large integer IDs survive intact when used as GUIDs.
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", endpoint, err)
+	}
+
+	articles, err := mapJSONItems(endpoint, doc, *feed.JSON)
+	if err != nil {
+		return nil, fmt.Errorf("mapping %s: %w", endpoint, err)
+	}
+	return articles, nil
+}
+
+// parseJSONMapping parses every configured path in opts, keyed by
+// field name. Unset fields are left out.
+func parseJSONMapping(opts model.JSONOptions) (map[string]jsonpath.Path, error) {
+	fields := map[string]string{
+		"items":   opts.Items,
+		"title":   opts.Title,
+		"url":     opts.URL,
+		"guid":    opts.GUID,
+		"date":    opts.Date,
+		"content": opts.Content,
+	}
+	paths := make(map[string]jsonpath.Path, len(fields))
+	for name, src := range fields {
+		if src == "" {
+			continue
+		}
+		p, err := jsonpath.Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		paths[name] = p
+	}
+	return paths, nil
+}
+
+// mapJSONItems maps the items of a decoded JSON document to articles.
+// An items path that reaches nothing is an error, since it usually means
+// the API's shape changed; an empty array is just an empty feed.
+func mapJSONItems(endpoint string, doc any, opts model.JSONOptions) ([]model.Article, error) {
+	paths, err := parseJSONMapping(opts)
+	if err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	found := paths["items"].Eval(doc)
+	if len(found) == 0 {
+		return nil, fmt.Errorf("items path %q matched nothing", opts.Items)
+	}
+	// WHY: "$.data.items" reaches one array, while "$.groups[*].items"
+	// may reach several; either way the items are their elements.
+	var items []any
+	for _, v := range found {
+		if arr, ok := v.([]any); ok {
+			items = append(items, arr...)
+		} else {
+			items = append(items, v)
+		}
+	}
+
+	var articles []model.Article
+	for _, item := range items {
+		a := model.Article{Title: jsonField(paths, item, "title")}
+		if a.Title == "" {
+			continue
+		}
+		// WHY: APIs often link to their own site by path alone, which
+		// resolves against the endpoint like a scraped page's links.
+		a.URL = resolveLink(base, jsonField(paths, item, "url"))
+		a.GUID = jsonField(paths, item, "guid")
+		a.Content = jsonField(paths, item, "content")
+		if p, ok := paths["date"]; ok {
+			if v, ok := p.First(item); ok {
+				// A date that doesn't parse leaves PublishedAt zero,
+				// which normalizeArticle turns into the fetch time.
+				a.PublishedAt, _ = jsonDate(v, opts.DateFormat)
+			}
+		}
+		if a.Content != "" {
+			a.Summary = firstLine(htmlText(a.Content), 200)
+		}
+		articles = append(articles, normalizeArticle(endpoint, a))
+	}
+	return articles, nil
+}
+
+// jsonField returns the named field of item as a string, or "" when
+// the field isn't mapped or its path reaches nothing.
+func jsonField(paths map[string]jsonpath.Path, item any, name string) string {
+	p, ok := paths[name]
+	if !ok {
+		return ""
+	}
+	v, ok := p.First(item)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(jsonString(v))
+}
+
+// jsonString renders a JSON value as text. Objects and arrays come back
+// as compact JSON, which is at least readable when mapped to content.
+func jsonString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+// jsonDate reads a date from a JSON value: a Unix timestamp in seconds
+// or milliseconds, or a string parsed like other extracted dates.
+func jsonDate(v any, layout string) (time.Time, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return unixDate(v.String())
+	case string:
+		if layout == "" {
+			if t, err := unixDate(v); err == nil {
+				return t, nil
+			}
+		}
+		return parseUserDate(v, layout)
+	}
+	return time.Time{}, fmt.Errorf("unsupported date value %v", v)
+}
+
+// unixDate parses a Unix timestamp. Values past the year 33658 in
+// seconds are taken to be milliseconds, which is what JavaScript-backed
+// APIs tend to send.
+func unixDate(s string) (time.Time, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if f > 1e12 {
+		return time.UnixMilli(int64(f)), nil
+	}
+	return time.Unix(int64(f), 0), nil
+}
@@ -0,0 +1,162 @@
+package feed
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+func TestJSONAPIItems(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/json-status.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("STATUS_TOKEN", "s3cret")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer s3cret" {
+			http.Error(w, "unauthorized: "+got, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	j := &JSONAPI{Client: srv.Client()}
+	articles, err := j.Items(t.Context(), model.Feed{
+		Name: "Status",
+		URL:  "json:" + srv.URL + "/api/incidents",
+		JSON: &model.JSONOptions{
+			Items:   "$.incidents[*]",
+			Title:   "name",
+			URL:     "shortlink",
+			GUID:    "$.id",
+			Date:    "created_at",
+			Content: "updates[0].body",
+			Headers: map[string]string{"Authorization": "Bearer $STATUS_TOKEN"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The untitled item is skipped.
+	if len(articles) != 2 {
+		t.Fatalf("got %d articles, want 2", len(articles))
+	}
+
+	a := articles[0]
+	if a.Title != "Elevated API latency" {
+		t.Errorf("Title = %q", a.Title)
+	}
+	// Large integer IDs aren't rounded through float64.
+	if a.GUID != "9007199254740993" {
+		t.Errorf("GUID = %q, want 9007199254740993", a.GUID)
+	}
+	if want := time.UnixMilli(1710408600000); !a.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", a.PublishedAt, want)
+	}
+	if a.Summary != "We are investigating slow responses from the eu-west cluster." {
+		t.Errorf("Summary = %q", a.Summary)
+	}
+	if a.NormalizedTitle == "" {
+		t.Error("NormalizedTitle not set")
+	}
+
+	// Relative links resolve against the endpoint.
+	b := articles[1]
+	if b.URL != srv.URL+"/i/41" {
+		t.Errorf("URL = %q, want %q", b.URL, srv.URL+"/i/41")
+	}
+	if want := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC); !b.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", b.PublishedAt, want)
+	}
+}
+
+func TestMapJSONItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     any
+		opts    model.JSONOptions
+		want    []string // titles
+		wantErr bool
+	}{
+		{
+			name: "top-level array",
+			doc:  []any{map[string]any{"t": "a"}, map[string]any{"t": "b"}},
+			opts: model.JSONOptions{Items: "$", Title: "t"},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "empty array",
+			doc:  map[string]any{"items": []any{}},
+			opts: model.JSONOptions{Items: "items", Title: "t"},
+		},
+		{
+			name:    "items path misses",
+			doc:     map[string]any{"data": []any{}},
+			opts:    model.JSONOptions{Items: "items", Title: "t"},
+			wantErr: true,
+		},
+		{
+			name: "GUID falls back to URL",
+			doc:  []any{map[string]any{"t": "a", "u": "https://example.com/a"}},
+			opts: model.JSONOptions{Items: "$", Title: "t", URL: "u"},
+			want: []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles, err := mapJSONItems("https://example.com/api", tt.doc, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(articles) != len(tt.want) {
+				t.Fatalf("got %d articles, want %d", len(articles), len(tt.want))
+			}
+			for i, a := range articles {
+				if a.Title != tt.want[i] {
+					t.Errorf("articles[%d].Title = %q, want %q", i, a.Title, tt.want[i])
+				}
+				if a.GUID == "" {
+					t.Errorf("articles[%d] has no GUID", i)
+				}
+				if tt.opts.URL != "" && a.GUID != a.URL {
+					t.Errorf("articles[%d].GUID = %q, want the URL", i, a.GUID)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONDate(t *testing.T) {
+	tests := []struct {
+		in     any
+		layout string
+		want   time.Time
+	}{
+		{json.Number("1710408600"), "", time.Unix(1710408600, 0)},
+		{json.Number("1710408600123"), "", time.UnixMilli(1710408600123)},
+		{"1710408600", "", time.Unix(1710408600, 0)},
+		{"2024-03-14T09:30:00Z", "", time.Date(2024, 3, 14, 9, 30, 0, 0, time.UTC)},
+		{"14/03/2024", "02/01/2006", time.Date(2024, 3, 14, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := jsonDate(tt.in, tt.layout)
+		if err != nil {
+			t.Errorf("jsonDate(%v) error: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("jsonDate(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := jsonDate(true, ""); err == nil {
+		t.Error("jsonDate(true) succeeded, want error")
+	}
+}
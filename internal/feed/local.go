@@ -0,0 +1,322 @@
+package feed
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mmcdole/gofeed"
+)
+
+// localPath extracts the filesystem path from a file:// or dir:// URL.
+func localPath(feedURL string) (string, error) {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", feedURL, err)
+	}
+	// WHY: Only the empty host and "localhost" mean this machine; any
+	// other host would be silently ignored if we just took the path.
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("%s: only local paths are supported (use %s:///path)", feedURL, u.Scheme)
+	}
+	if u.Path == "" {
+		return "", fmt.Errorf("%s: missing path", feedURL)
+	}
+	return u.Path, nil
+}
+
+// ParseFile parses a feed document (RSS, Atom or JSON Feed) from a
+// file:// URL, with no HTTP involved.
+func ParseFile(feedURL string) ([]model.Article, error) {
+	path, err := localPath(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening feed file: %w", err)
+	}
+	defer f.Close()
+
+	parsed, err := gofeed.NewParser().Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing feed file %s: %w", path, err)
+	}
+	return mapItems(feedURL, parsed), nil
+}
+
+// ParseDir turns every Markdown and HTML file under a dir:// URL into
+// an article. Hidden files and directories are skipped, as are
+// documents whose front matter marks them as drafts.
+func ParseDir(feedURL string) ([]model.Article, error) {
+	root, err := localPath(feedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var articles []model.Article
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || docKind(path) == "" {
+			return nil
+		}
+
+		a, draft, err := readDoc(path)
+		if err != nil {
+			return err
+		}
+		if !draft {
+			articles = append(articles, a)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading directory feed %s: %w", root, err)
+	}
+	return articles, nil
+}
+
+// docKind classifies a file by extension as "markdown", "html" or "".
+func docKind(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return "markdown"
+	case ".html", ".htm":
+		return "html"
+	}
+	return ""
+}
+
+// frontMatter holds the document metadata we understand.
+type frontMatter struct {
+	Title       string `toml:"title"`
+	Date        string `toml:"-"`
+	Author      string `toml:"author"`
+	Summary     string `toml:"summary"`
+	Description string `toml:"description"`
+	Draft       bool   `toml:"draft"`
+}
+
+// readDoc maps one document to an article, reporting drafts.
+func readDoc(path string) (model.Article, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return model.Article{}, false, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return model.Article{}, false, err
+	}
+
+	fm, body, err := splitFrontMatter(data)
+	if err != nil {
+		return model.Article{}, false, fmt.Errorf("front matter in %s: %w", path, err)
+	}
+
+	kind := docKind(path)
+	text := string(body)
+	if kind == "html" {
+		text = htmlText(string(body))
+	}
+
+	title := fm.Title
+	if title == "" {
+		title = docTitle(kind, string(body))
+	}
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	summary := fm.Summary
+	if summary == "" {
+		summary = fm.Description
+	}
+	if summary == "" {
+		summary = truncate(firstParagraph(text), 200)
+	}
+
+	// WHY: Files are often touched by syncs and checkouts, so the mod
+	// time is only a fallback for documents without a date of their own.
+	published := info.ModTime()
+	if fm.Date != "" {
+		t, err := parseDocDate(fm.Date)
+		if err != nil {
+			return model.Article{}, false, fmt.Errorf("date in %s: %w", path, err)
+		}
+		published = t
+	}
+
+	link := (&url.URL{Scheme: "file", Path: path}).String()
+	return model.Article{
+		GUID:            link,
+		Title:           title,
+		URL:             link,
+		Author:          fm.Author,
+		Summary:         summary,
+		Content:         strings.TrimSpace(string(body)),
+		PublishedAt:     published,
+		FetchedAt:       time.Now(),
+		NormalizedTitle: NormalizeTitle(title),
+	}, fm.Draft, nil
+}
+
+// splitFrontMatter separates TOML (+++) or YAML (---) front matter
+// from the document body. Line endings are normalised to "\n", in the
+// body as well as the header.
+func splitFrontMatter(data []byte) (frontMatter, []byte, error) {
+	var fm frontMatter
+	// WHY: Documents saved on Windows end lines with "\r\n", which would
+	// hide the fences from the "\n"-delimited matching below.
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	for _, delim := range []string{"+++", "---"} {
+		open := delim + "\n"
+		if !bytes.HasPrefix(data, []byte(open)) {
+			continue
+		}
+		rest := data[len(open):]
+		end := bytes.Index(rest, []byte("\n"+delim))
+		if end < 0 {
+			return fm, data, fmt.Errorf("unterminated %s block", delim)
+		}
+		header, body := rest[:end], rest[end+1+len(delim):]
+
+		if delim == "+++" {
+			var err error
+			if fm, err = parseTOMLFrontMatter(header); err != nil {
+				return fm, data, err
+			}
+		} else {
+			fm = parseYAMLFrontMatter(header)
+		}
+		return fm, bytes.TrimLeft(body, "\n"), nil
+	}
+	return fm, data, nil
+}
+
+// parseTOMLFrontMatter decodes TOML front matter, where the date may be
+// a native TOML datetime rather than a string.
+func parseTOMLFrontMatter(header []byte) (frontMatter, error) {
+	var raw struct {
+		frontMatter
+		Date any `toml:"date"`
+	}
+	if err := toml.Unmarshal(header, &raw); err != nil {
+		return frontMatter{}, err
+	}
+	fm := raw.frontMatter
+	switch d := raw.Date.(type) {
+	case time.Time:
+		fm.Date = d.Format(time.RFC3339)
+	case string:
+		fm.Date = d
+	}
+	return fm, nil
+}
+
+// parseYAMLFrontMatter reads the flat "key: value" lines of YAML front
+// matter. Nested structures and lists are ignored — the fields we use
+// are all scalars, and this avoids pulling in a YAML library.
+func parseYAMLFrontMatter(header []byte) frontMatter {
+	var fm frontMatter
+	sc := bufio.NewScanner(bytes.NewReader(header))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' {
+			continue
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		val = strings.TrimSpace(val)
+		if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+			val = val[1 : len(val)-1]
+		}
+		switch strings.TrimSpace(key) {
+		case "title":
+			fm.Title = val
+		case "date":
+			fm.Date = val
+		case "author":
+			fm.Author = val
+		case "summary":
+			fm.Summary = val
+		case "description":
+			fm.Description = val
+		case "draft":
+			fm.Draft = val == "true"
+		}
+	}
+	return fm
+}
+
+// docDateLayouts are the date shapes static site generators write.
+var docDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseDocDate parses a front matter date in local time unless it
+// carries its own zone.
+func parseDocDate(s string) (time.Time, error) {
+	for _, layout := range docDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", s)
+}
+
+// mdHeading matches a Markdown level-1 heading, and htmlHeading an
+// HTML <title> or <h1>.
+var (
+	mdHeading   = regexp.MustCompile(`(?m)^#\s+(.+?)\s*#*\s*$`)
+	htmlHeading = regexp.MustCompile(`(?is)<(title|h1)[^>]*>(.*?)</(title|h1)>`)
+)
+
+// docTitle finds a title in the document body itself.
+func docTitle(kind, body string) string {
+	if kind == "html" {
+		if m := htmlHeading.FindStringSubmatch(body); m != nil {
+			return strings.TrimSpace(htmlText(m[2]))
+		}
+		return ""
+	}
+	if m := mdHeading.FindStringSubmatch(body); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// firstParagraph returns the first block of prose, skipping headings.
+func firstParagraph(text string) string {
+	for _, block := range strings.Split(text, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" || strings.HasPrefix(block, "#") {
+			continue
+		}
+		return strings.Join(strings.Fields(block), " ")
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates files (relative path → content) under a temp dir.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestParseFile(t *testing.T) {
+	root := writeFiles(t, map[string]string{"feed.xml": `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Build</title>
+<entry><id>urn:build:1</id><title>Site rebuilt</title><link href="https://site.example/"/><updated>2024-05-01T10:00:00Z</updated></entry>
+</feed>`})
+
+	articles, err := ParseFile("file://" + filepath.ToSlash(root) + "/feed.xml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Title != "Site rebuilt" || articles[0].GUID != "urn:build:1" {
+		t.Errorf("articles = %+v", articles)
+	}
+
+	if _, err := ParseFile("file:///does/not/exist.xml"); err == nil {
+		t.Error("expected error for missing file")
+	}
+	if _, err := ParseFile("file://remote.example/feed.xml"); err == nil {
+		t.Error("expected error for non-local host")
+	}
+}
+
+func TestParseDir(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"yaml.md": `---
+title: "Incident review: May outage"
+date: 2024-05-03
+author: alice
+tags:
+  - ops
+---
+
+The database failover took longer than expected.
+
+More detail follows.
+`,
+		"notes/toml.md": `+++
+title = "Release checklist"
+date = 2024-04-01T09:00:00Z
+summary = "What to do before tagging."
++++
+Body.
+`,
+		"heading.md": "# Heading title\n\nFirst paragraph\nwraps here.\n",
+		"page.html":  "<html><head><title>HTML &amp; notes</title></head><body><p>Hello <b>there</b>.</p></body></html>",
+		"draft.md":   "---\ntitle: Not yet\ndraft: true\n---\nWIP\n",
+		"crlf.md":    "---\r\ntitle: Saved on Windows\r\ndraft: true\r\n---\r\nWIP\r\n",
+		".hidden.md": "# Hidden\n",
+		".git/x.md":  "# Repo internals\n",
+		"image.png":  "not a doc",
+	})
+
+	articles, err := ParseDir("dir://" + filepath.ToSlash(root))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byTitle := make(map[string]int)
+	for i, a := range articles {
+		byTitle[a.Title] = i
+	}
+	if len(articles) != 4 {
+		t.Fatalf("got %d articles (%v), want 4", len(articles), byTitle)
+	}
+
+	yaml := articles[byTitle["Incident review: May outage"]]
+	if yaml.Author != "alice" || yaml.PublishedAt.Format("2006-01-02") != "2024-05-03" {
+		t.Errorf("YAML doc Author, date = %q, %v", yaml.Author, yaml.PublishedAt)
+	}
+	if yaml.Summary != "The database failover took longer than expected." {
+		t.Errorf("YAML doc Summary = %q", yaml.Summary)
+	}
+	if want := "file://" + filepath.ToSlash(root) + "/yaml.md"; yaml.URL != want || yaml.GUID != want {
+		t.Errorf("URL, GUID = %q, %q, want %q", yaml.URL, yaml.GUID, want)
+	}
+
+	toml := articles[byTitle["Release checklist"]]
+	if toml.Summary != "What to do before tagging." || toml.PublishedAt.Format("2006-01-02 15:04") != "2024-04-01 09:00" {
+		t.Errorf("TOML doc Summary, date = %q, %v", toml.Summary, toml.PublishedAt.UTC())
+	}
+
+	heading := articles[byTitle["Heading title"]]
+	if heading.Summary != "First paragraph wraps here." {
+		t.Errorf("heading doc Summary = %q", heading.Summary)
+	}
+
+	html, ok := byTitle["HTML & notes"]
+	if !ok || articles[html].Summary != "Hello there." {
+		t.Errorf("HTML doc missing or wrong: %v", articles)
+	}
+}
+
+func TestParseDocDate(t *testing.T) {
+	for _, s := range []string{"2024-05-03", "2024-05-03 10:30", "2024-05-03T10:30:00Z", "2024-05-03 10:30:00 +0200"} {
+		if _, err := parseDocDate(s); err != nil {
+			t.Errorf("parseDocDate(%q): %v", s, err)
+		}
+	}
+	if _, err := parseDocDate("May 3rd"); err == nil {
+		t.Error("expected error for unrecognised date")
+	}
+}
@@ -0,0 +1,347 @@
+package feed
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"golang.org/x/net/html/charset"
+)
+
+// maxMessageSize bounds how much of one message is read. Newsletters
+// are rarely over a megabyte; anything far larger is attachments.
+const maxMessageSize = 16 << 20
+
+// maxMIMEDepth bounds how deeply nested multipart bodies are walked.
+const maxMIMEDepth = 8
+
+// wordDecoder decodes RFC 2047 encoded words ("=?utf-8?q?...?=") in any
+// charset the HTML charset table knows, not just UTF-8 and Latin-1.
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// ParseMail reads the messages in a mail: feed's Maildir or mbox and
+// returns those from the configured senders or lists as articles.
+// Messages dated before since are skipped: a mailbox keeps everything,
+// and old messages would otherwise reappear as new after they expire.
+func ParseMail(feed model.Feed, since time.Time) ([]model.Article, error) {
+	p, err := mailboxPath(feed.Target())
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("opening mailbox: %w", err)
+	}
+
+	filter := mailFilter{}
+	if feed.Mail != nil {
+		filter = mailFilter{from: feed.Mail.From, listIDs: feed.Mail.ListID}
+	}
+
+	var articles []model.Article
+	skipped := 0
+	each := func(raw []byte, received time.Time) {
+		a, ok, err := mailArticle(p, raw, received, since, filter)
+		if err != nil {
+			skipped++
+			return
+		}
+		if ok {
+			articles = append(articles, a)
+		}
+	}
+
+	if info.IsDir() {
+		err = readMaildir(p, since, each)
+	} else {
+		err = readMbox(p, each)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading mailbox %s: %w", p, err)
+	}
+	if skipped > 0 {
+		log.Warn("Skipped unreadable messages", "mailbox", p, "count", skipped)
+	}
+	return articles, nil
+}
+
+// mailboxPath expands a leading "~/" in a mail: feed's path.
+func mailboxPath(p string) (string, error) {
+	if p == "" {
+		return "", fmt.Errorf("mail feed: missing mailbox path")
+	}
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, rest), nil
+	}
+	return p, nil
+}
+
+// readMaildir calls fn for each message in a Maildir's new/ and cur/
+// directories. Files last modified before since are skipped without
+// being read, since delivery time bounds the date of the message.
+func readMaildir(dir string, since time.Time, fn func([]byte, time.Time)) error {
+	found := false
+	for _, sub := range []string{"new", "cur"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		found = true
+		for _, e := range entries {
+			if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil || info.ModTime().Before(since) || info.Size() > maxMessageSize {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, sub, e.Name()))
+			if err != nil {
+				return err
+			}
+			fn(data, info.ModTime())
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s is a directory but not a Maildir (no cur/ or new/)", dir)
+	}
+	return nil
+}
+
+// mboxFromQuote matches a body line that mbox writers escaped with '>'
+// because it began with "From ".
+var mboxFromQuote = regexp.MustCompile(`^>+From `)
+
+// readMbox calls fn for each message in an mbox file. Messages are
+// separated by "From " lines; mboxrd's ">From " quoting is undone.
+// Messages over maxMessageSize are skipped.
+func readMbox(p string, fn func([]byte, time.Time)) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		msg       bytes.Buffer
+		inMsg     bool
+		tooLarge  bool
+		prevBlank = true
+	)
+	flush := func() {
+		if inMsg && !tooLarge && msg.Len() > 0 {
+			fn(bytes.Clone(msg.Bytes()), time.Time{})
+		}
+		msg.Reset()
+		tooLarge = false
+	}
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			switch {
+			case prevBlank && bytes.HasPrefix(line, []byte("From ")):
+				flush()
+				inMsg = true
+			case !inMsg || tooLarge:
+			case msg.Len()+len(line) > maxMessageSize:
+				tooLarge = true
+			default:
+				if mboxFromQuote.Match(line) {
+					line = line[1:]
+				}
+				msg.Write(line)
+			}
+			prevBlank = len(bytes.TrimRight(line, "\r\n")) == 0
+		}
+		if err == io.EOF {
+			flush()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// mailFilter selects messages by sender address glob ("*@substack.com")
+// or List-Id substring. With neither configured, every message matches.
+type mailFilter struct {
+	from    []string
+	listIDs []string
+}
+
+// match reports whether a message from addr with the given List-Id
+// header passes the filter.
+func (f mailFilter) match(addr, listID string) bool {
+	if len(f.from) == 0 && len(f.listIDs) == 0 {
+		return true
+	}
+	addr = strings.ToLower(addr)
+	for _, pattern := range f.from {
+		if ok, _ := path.Match(strings.ToLower(pattern), addr); ok {
+			return true
+		}
+	}
+	listID = strings.ToLower(listID)
+	for _, id := range f.listIDs {
+		if listID != "" && strings.Contains(listID, strings.ToLower(id)) {
+			return true
+		}
+	}
+	return false
+}
+
+// mailArticle maps one raw message to an article. ok is false for
+// messages the filter rejects or that are older than since.
+func mailArticle(mailbox string, raw []byte, received, since time.Time, filter mailFilter) (a model.Article, ok bool, err error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return a, false, err
+	}
+	h := msg.Header
+
+	var fromName, fromAddr string
+	parser := mail.AddressParser{WordDecoder: wordDecoder}
+	if from, err := parser.Parse(h.Get("From")); err == nil {
+		fromName, fromAddr = from.Name, from.Address
+	}
+	if !filter.match(fromAddr, h.Get("List-Id")) {
+		return a, false, nil
+	}
+
+	date, err := h.Date()
+	if err != nil {
+		date = received
+	}
+	if date.IsZero() {
+		date = time.Now()
+	}
+	if date.Before(since) {
+		return a, false, nil
+	}
+
+	htmlBody, textBody := mailBody(h.Get("Content-Type"), h.Get("Content-Transfer-Encoding"), msg.Body, 0)
+
+	subject, err := wordDecoder.DecodeHeader(h.Get("Subject"))
+	if err != nil {
+		subject = h.Get("Subject")
+	}
+	a = model.Article{
+		Title:       strings.Join(strings.Fields(subject), " "),
+		Author:      fromName,
+		PublishedAt: date,
+		FetchedAt:   time.Now(),
+		// RFC 5064: some list servers link the web copy of a message.
+		URL: strings.Trim(h.Get("Archived-At"), "<> "),
+	}
+	if a.Title == "" {
+		a.Title = "(no subject)"
+	}
+	if a.Author == "" {
+		a.Author = fromAddr
+	}
+
+	if htmlBody != "" {
+		a.Content = htmlMarkdown(htmlBody)
+	} else {
+		a.Content = strings.TrimSpace(strings.ReplaceAll(textBody, "\r\n", "\n"))
+	}
+	a.Summary = truncate(firstParagraph(a.Content), 200)
+
+	// LEARN: "mid:" is the RFC 2392 URL scheme for a Message-ID, which
+	// makes the GUID self-describing and stable across mailbox moves.
+	if id := strings.Trim(h.Get("Message-Id"), "<> "); id != "" {
+		a.GUID = "mid:" + id
+	} else {
+		sum := sha256.Sum256([]byte(mailbox + "|" + fromAddr + "|" + subject + "|" + date.UTC().Format(time.RFC3339)))
+		a.GUID = fmt.Sprintf("sha256:%x", sum[:8])
+	}
+	a.NormalizedTitle = NormalizeTitle(a.Title)
+	return a, true, nil
+}
+
+// mailBody walks a message body and returns its first HTML and first
+// plain-text parts, decoded to UTF-8. Attachments are skipped.
+func mailBody(contentType, encoding string, r io.Reader, depth int) (htmlBody, textBody string) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMIMEDepth || params["boundary"] == "" {
+			return "", ""
+		}
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			if disp, _, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition")); disp == "attachment" {
+				continue
+			}
+			// LEARN: multipart.Reader decodes quoted-printable parts
+			// itself and removes the header, so only base64 is left for
+			// the recursive call to undo.
+			h, t := mailBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, depth+1)
+			if htmlBody == "" {
+				htmlBody = h
+			}
+			if textBody == "" {
+				textBody = t
+			}
+		}
+		return htmlBody, textBody
+	}
+
+	if mediaType != "text/html" && mediaType != "text/plain" {
+		return "", ""
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		r = quotedprintable.NewReader(r)
+	}
+	if cs := params["charset"]; cs != "" && !strings.EqualFold(cs, "utf-8") && !strings.EqualFold(cs, "us-ascii") {
+		if cr, err := charset.NewReaderLabel(cs, r); err == nil {
+			r = cr
+		}
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxMessageSize))
+	if err != nil && len(data) == 0 {
+		return "", ""
+	}
+
+	if mediaType == "text/html" {
+		return string(data), ""
+	}
+	return "", string(data)
+}
@@ -0,0 +1,126 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+func TestParseMail_Maildir(t *testing.T) {
+	feed := model.Feed{
+		Name: "Newsletters",
+		URL:  "mail:testdata/maildir",
+		Mail: &model.MailOptions{
+			From:   []string{"*@*.substack.com"},
+			ListID: []string{"golangweekly.cooperpress.com"},
+		},
+	}
+	articles, err := ParseMail(feed, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byGUID := make(map[string]model.Article)
+	for _, a := range articles {
+		byGUID[a.GUID] = a
+	}
+	if len(byGUID) != 2 {
+		t.Fatalf("got %d articles, want 2 (the personal mail is filtered out): %v", len(articles), articles)
+	}
+
+	weekly, ok := byGUID["mid:issue-500@golangweekly.com"]
+	if !ok {
+		t.Fatal("Go Weekly issue missing")
+	}
+	if weekly.Title != "Go Weekly — Issue 500" {
+		t.Errorf("Title = %q", weekly.Title)
+	}
+	if weekly.Author != "Go Weekly" {
+		t.Errorf("Author = %q", weekly.Author)
+	}
+	if want := time.Date(2024, 3, 14, 15, 0, 0, 0, time.UTC); !weekly.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", weekly.PublishedAt, want)
+	}
+	// The HTML part wins over the plain-text one, minus the hidden
+	// preheader and tracking pixel.
+	for _, want := range []string{
+		"# Go Weekly — Issue 500",
+		"Welcome to the **500th** issue!",
+		"[Range over func, explained](https://golangweekly.com/link/1)",
+		"- New `slices` helpers",
+	} {
+		if !strings.Contains(weekly.Content, want) {
+			t.Errorf("Content missing %q:\n%s", want, weekly.Content)
+		}
+	}
+	for _, unwanted := range []string{"This week: generics", "open/abc.gif", "plain text version"} {
+		if strings.Contains(weekly.Content, unwanted) {
+			t.Errorf("Content contains %q:\n%s", unwanted, weekly.Content)
+		}
+	}
+	if weekly.Summary != "Welcome to the **500th** issue!" {
+		t.Errorf("Summary = %q", weekly.Summary)
+	}
+
+	lisbon, ok := byGUID["mid:lisbon.1@substack.com"]
+	if !ok {
+		t.Fatal("Substack post missing")
+	}
+	// Base64 body and headers in ISO-8859-1 come out as UTF-8.
+	if lisbon.Author != "José" {
+		t.Errorf("Author = %q, want José", lisbon.Author)
+	}
+	if lisbon.Content != "Café culture and _déjà vu_." {
+		t.Errorf("Content = %q", lisbon.Content)
+	}
+	if lisbon.URL != "https://jose.substack.com/p/notes-from-lisbon" {
+		t.Errorf("URL = %q, want the Archived-At link", lisbon.URL)
+	}
+}
+
+func TestParseMail_Mbox(t *testing.T) {
+	feed := model.Feed{
+		Name: "Rust",
+		URL:  "mail:testdata/newsletters.mbox",
+		Mail: &model.MailOptions{ListID: []string{"twir.this-week-in-rust.org"}},
+	}
+	// Issue 528 is older than the retention window.
+	articles, err := ParseMail(feed, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 {
+		t.Fatalf("got %d articles, want 1: %v", len(articles), articles)
+	}
+	a := articles[0]
+	if a.Title != "This Week in Rust 537" {
+		t.Errorf("Title = %q", a.Title)
+	}
+	if want := "Hello Rustaceans!\n\nFrom the community this week: async closures."; a.Content != want {
+		t.Errorf("Content = %q, want %q", a.Content, want)
+	}
+}
+
+func TestMailFilter(t *testing.T) {
+	f := mailFilter{from: []string{"*@Substack.com", "news@example.com"}, listIDs: []string{"golang-nuts"}}
+	tests := []struct {
+		addr, listID string
+		want         bool
+	}{
+		{"writer@substack.com", "", true},
+		{"NEWS@example.com", "", true},
+		{"other@example.com", "", false},
+		{"someone@example.com", "golang-nuts <golang-nuts.googlegroups.com>", true},
+		{"someone@example.com", "rust-users <rust.example>", false},
+	}
+	for _, tt := range tests {
+		if got := f.match(tt.addr, tt.listID); got != tt.want {
+			t.Errorf("match(%q, %q) = %v, want %v", tt.addr, tt.listID, got, tt.want)
+		}
+	}
+	if !(mailFilter{}).match("anyone@example.com", "") {
+		t.Error("empty filter should match everything")
+	}
+}
@@ -0,0 +1,253 @@
+package feed
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlMarkdown converts an HTML document or fragment to Markdown for
+// the reader: headings, emphasis, links, lists, quotes, code and images
+// survive; layout markup is flattened into paragraphs. Hidden elements,
+// such as the preheader text newsletters put in their first line, are
+// dropped along with scripts and styles.
+func htmlMarkdown(s string) string {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return htmlText(s)
+	}
+	return tidyMarkdown(mdChildren(doc))
+}
+
+// mdChildren renders the children of n.
+func mdChildren(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(mdNode(c))
+	}
+	return b.String()
+}
+
+// mdNode renders one node. Block elements are wrapped in blank lines,
+// which tidyMarkdown later collapses.
+func mdNode(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		// LEARN: strings.Fields splits on unicode.IsSpace, which
+		// includes the non-breaking spaces that &nbsp; decodes to.
+		text := strings.Join(strings.Fields(n.Data), " ")
+		if text == "" {
+			if n.Data != "" {
+				return " "
+			}
+			return ""
+		}
+		if startsSpace(n.Data) {
+			text = " " + text
+		}
+		if endsSpace(n.Data) {
+			text += " "
+		}
+		return text
+	case html.ElementNode:
+	case html.DocumentNode:
+		return mdChildren(n)
+	default:
+		return ""
+	}
+
+	if skippedElements[n.Data] || n.DataAtom == atom.Title || hidden(n) {
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := oneLine(mdChildren(n))
+		if text == "" {
+			return ""
+		}
+		level := int(n.Data[1] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + text + "\n\n"
+	case atom.Br:
+		return "\n"
+	case atom.Hr:
+		return "\n\n---\n\n"
+	case atom.A:
+		text := strings.TrimSpace(mdChildren(n))
+		href := strings.TrimSpace(attr(n, "href"))
+		// WHY: Links wrapping block content (a whole card, a banner
+		// image) can't be expressed inline; keep just the content.
+		if text == "" || href == "" || strings.HasPrefix(href, "#") || strings.Contains(text, "\n") {
+			return text
+		}
+		if text == href {
+			return "<" + href + ">"
+		}
+		return "[" + text + "](" + href + ")"
+	case atom.Strong, atom.B:
+		return wrapInline(mdChildren(n), "**")
+	case atom.Em, atom.I:
+		return wrapInline(mdChildren(n), "_")
+	case atom.Code:
+		if text := textContent(n); text != "" {
+			return "`" + text + "`"
+		}
+		return ""
+	case atom.Pre:
+		return "\n\n```\n" + strings.Trim(textContent(n), "\n") + "\n```\n\n"
+	case atom.Img:
+		alt, src := strings.TrimSpace(attr(n, "alt")), attr(n, "src")
+		// WHY: Newsletters embed 1×1 tracking pixels, usually without
+		// alt text; images that don't describe themselves are dropped.
+		if alt == "" || src == "" || attr(n, "width") == "1" || attr(n, "height") == "1" {
+			return ""
+		}
+		return "![" + alt + "](" + src + ")"
+	case atom.Ul, atom.Ol:
+		return "\n\n" + mdList(n) + "\n\n"
+	case atom.Blockquote:
+		inner := tidyMarkdown(mdChildren(n))
+		if inner == "" {
+			return ""
+		}
+		lines := strings.Split(inner, "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight("> "+l, " ")
+		}
+		return "\n\n" + strings.Join(lines, "\n") + "\n\n"
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main,
+		atom.Nav, atom.Aside, atom.Table, atom.Tbody, atom.Thead, atom.Tr, atom.Td, atom.Th,
+		atom.Center, atom.Dl, atom.Dt, atom.Dd, atom.Figure, atom.Figcaption, atom.Li:
+		// WHY: Newsletters lay out with nested tables. Treating every
+		// cell as a paragraph reads far better than pipe tables would.
+		return "\n\n" + mdChildren(n) + "\n\n"
+	}
+	return mdChildren(n)
+}
+
+// mdList renders a ul or ol. Nested lists are indented under their item.
+func mdList(n *html.Node) string {
+	var items []string
+	i := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		i = start
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(i) + ". "
+			i++
+		}
+		body := tidyMarkdown(mdChildren(c))
+		indent := strings.Repeat(" ", len(marker))
+		lines := strings.Split(body, "\n")
+		for j := range lines {
+			if j > 0 && lines[j] != "" {
+				lines[j] = indent + lines[j]
+			}
+		}
+		items = append(items, marker+strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+// tidyMarkdown trims the spacing that rendering leaves behind: blank
+// line runs, trailing spaces, and stray spaces at the start of lines
+// or between words. Fenced code blocks are left untouched.
+func tidyMarkdown(s string) string {
+	lines := strings.Split(s, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			lines[i] = strings.TrimSpace(line)
+			continue
+		}
+		if inFence {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		// A single leading space is rendering slack; two or more is
+		// list indentation.
+		if indent < 2 {
+			indent = 0
+		}
+		lines[i] = line[:indent] + strings.Join(strings.Fields(line[indent:]), " ")
+	}
+	return strings.TrimSpace(collapseBlankLines(strings.Join(lines, "\n")))
+}
+
+// wrapInline surrounds inline text with a marker, keeping the spaces
+// that separate it from its neighbours outside the marker.
+func wrapInline(s, marker string) string {
+	text := strings.TrimSpace(s)
+	if text == "" {
+		return s
+	}
+	out := marker + text + marker
+	if startsSpace(s) {
+		out = " " + out
+	}
+	if endsSpace(s) {
+		out += " "
+	}
+	return out
+}
+
+// oneLine collapses rendered Markdown onto a single line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// textContent returns the raw text under n, whitespace preserved.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+// attr returns the value of n's attribute key, or "".
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hidden reports whether an element is styled or marked not to display.
+func hidden(n *html.Node) bool {
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "hidden":
+			return true
+		case "style":
+			style := strings.ReplaceAll(strings.ToLower(a.Val), " ", "")
+			if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// startsSpace and endsSpace report whether s begins or ends with
+// whitespace, counting non-breaking spaces.
+func startsSpace(s string) bool {
+	return s != "" && strings.TrimLeft(s, " \t\r\n\u00a0") != s
+}
+
+func endsSpace(s string) bool {
+	return s != "" && strings.TrimRight(s, " \t\r\n\u00a0") != s
+}
@@ -0,0 +1,54 @@
+package feed
+
+import "testing"
+
+func TestHTMLMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "paragraphs and inline",
+			in:   "<p>Hello <b>bold</b> and <em>soft</em>\n  words.</p><p>Second&nbsp;para with <a href=\"https://go.dev\">a link</a>.</p>",
+			want: "Hello **bold** and _soft_ words.\n\nSecond para with [a link](https://go.dev).",
+		},
+		{
+			name: "headings and rules",
+			in:   "<h1>Issue #42</h1><hr><h3>In  brief</h3>",
+			want: "# Issue #42\n\n---\n\n### In brief",
+		},
+		{
+			name: "lists",
+			in:   "<ul><li>one</li><li>two<ol start=\"3\"><li>three</li><li>four</li></ol></li></ul>",
+			want: "- one\n- two\n\n  3. three\n  4. four",
+		},
+		{
+			name: "quote and code",
+			in:   "<blockquote><p>Quoted</p><p>twice</p></blockquote><pre><code>if x {\n\treturn\n}</code></pre><p>Use <code>go vet</code>.</p>",
+			want: "> Quoted\n>\n> twice\n\n```\nif x {\n\treturn\n}\n```\n\nUse `go vet`.",
+		},
+		{
+			name: "newsletter layout",
+			in: `<html><head><title>x</title><style>p{}</style></head><body>
+				<div style="display: none">Preheader text you never see</div>
+				<table><tr><td><a href="https://example.com/view"><img src="https://example.com/banner.png" alt="Weekly"></a></td></tr>
+				<tr><td><p>Top story</p></td><td>Sidebar</td></tr></table>
+				<img src="https://t.example.com/open.gif" width="1" height="1" alt="">
+				</body></html>`,
+			want: "[![Weekly](https://example.com/banner.png)](https://example.com/view)\n\nTop story\n\nSidebar",
+		},
+		{
+			name: "bare link",
+			in:   `<p>See <a href="https://go.dev/doc">https://go.dev/doc</a></p>`,
+			want: "See <https://go.dev/doc>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := htmlMarkdown(tt.in); got != tt.want {
+				t.Errorf("htmlMarkdown() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+const mastodonStatusesJSON = `[
+  {"id": "103", "uri": "https://social.example/users/bob/statuses/103/activity", "url": "https://social.example/@bob/103",
+   "created_at": "2024-05-03T12:00:00.000Z", "content": "", "spoiler_text": "",
+   "account": {"id": "1", "acct": "bob"},
+   "reblog": {"id": "99", "uri": "https://other.example/users/carol/statuses/99", "url": "https://other.example/@carol/99",
+              "created_at": "2024-05-02T09:00:00.000Z", "content": "<p>Go 1.23 is out! 🎉</p>",
+              "account": {"id": "7", "acct": "carol@other.example"}, "media_attachments": []},
+   "media_attachments": []},
+  {"id": "102", "uri": "https://social.example/users/bob/statuses/102", "url": "https://social.example/@bob/102",
+   "created_at": "2024-05-02T08:00:00.000Z", "content": "<p>Spoilers for the finale</p>", "spoiler_text": "TV spoilers",
+   "account": {"id": "1", "acct": "bob"}, "media_attachments": []},
+  {"id": "101", "uri": "https://social.example/users/bob/statuses/101", "url": "https://social.example/@bob/101",
+   "created_at": "2024-05-01T08:00:00.000Z", "content": "<p>New desk setup<br>with &amp; without lamp</p>", "spoiler_text": "",
+   "account": {"id": "1", "acct": "bob"},
+   "media_attachments": [{"type": "image", "url": "https://files.example/desk.jpg", "description": "A tidy desk"}]}
+]`
+
+func TestMastodonTimeline_Account(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/accounts/lookup":
+			if got := r.URL.Query().Get("acct"); got != "bob" {
+				t.Errorf("lookup acct = %q", got)
+			}
+			fmt.Fprint(w, `{"id":"1","acct":"bob"}`)
+		case "/api/v1/accounts/1/statuses":
+			if r.URL.Query().Get("exclude_replies") != "true" {
+				t.Error("account timeline should exclude replies")
+			}
+			if r.URL.Query().Get("since_id") == "103" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			fmt.Fprint(w, mastodonStatusesJSON)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	m := &Mastodon{Client: srv.Client()}
+
+	articles, cursor, err := m.Timeline(t.Context(), "@bob@"+host, store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("got %d articles, want 3", len(articles))
+	}
+	if cursor.LatestID != "103" {
+		t.Errorf("LatestID = %q, want 103", cursor.LatestID)
+	}
+
+	boost := articles[0]
+	if boost.Title != "@carol@other.example: Go 1.23 is out! 🎉" {
+		t.Errorf("boost Title = %q", boost.Title)
+	}
+	if boost.Author != "carol@other.example" || boost.URL != "https://other.example/@carol/99" {
+		t.Errorf("boost Author, URL = %q, %q", boost.Author, boost.URL)
+	}
+	if !strings.Contains(boost.Content, "Boosted by @bob") {
+		t.Errorf("boost Content = %q", boost.Content)
+	}
+
+	cw := articles[1]
+	if cw.Title != "CW: TV spoilers" || strings.Contains(cw.Summary, "finale") {
+		t.Errorf("content warning leaked: Title %q, Summary %q", cw.Title, cw.Summary)
+	}
+	if !strings.Contains(cw.Content, "Content warning:</strong> TV spoilers") {
+		t.Errorf("CW Content = %q", cw.Content)
+	}
+
+	media := articles[2]
+	if media.Title != "New desk setup" || media.Summary != "New desk setup\nwith & without lamp" {
+		t.Errorf("media Title, Summary = %q, %q", media.Title, media.Summary)
+	}
+	if !strings.Contains(media.Content, `<a href="https://files.example/desk.jpg">[image: A tidy desk]</a>`) {
+		t.Errorf("media Content = %q", media.Content)
+	}
+
+	// A refresh passes since_id and gets nothing new.
+	articles, next, err := m.Timeline(t.Context(), "@bob@"+host, cursor)
+	if err != nil || len(articles) != 0 || next.LatestID != "103" {
+		t.Errorf("refresh = %d articles, LatestID %q, err %v", len(articles), next.LatestID, err)
+	}
+}
+
+func TestMastodonTimeline_PagesToCursor(t *testing.T) {
+	// Statuses 200 down to 151 arrived since the cursor at 150.
+	statuses := func(from, to int) string {
+		var parts []string
+		for id := from; id >= to; id-- {
+			parts = append(parts, fmt.Sprintf(`{"id":"%d","uri":"https://social.example/statuses/%d","created_at":"2024-05-01T08:00:00.000Z","content":"<p>Post %d</p>","account":{"id":"1","acct":"bob"}}`, id, id, id))
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("since_id") != "150" {
+			t.Errorf("since_id = %q, want 150", q.Get("since_id"))
+		}
+		switch q.Get("max_id") {
+		case "":
+			fmt.Fprint(w, statuses(200, 161))
+		case "161":
+			fmt.Fprint(w, statuses(160, 151))
+		default:
+			t.Errorf("unexpected max_id %q", q.Get("max_id"))
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	m := &Mastodon{Client: srv.Client()}
+	articles, next, err := m.Timeline(t.Context(), "#golang@"+host, store.Cursor{LatestID: "150"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 50 {
+		t.Errorf("got %d articles, want all 50 since the cursor", len(articles))
+	}
+	if next.LatestID != "200" {
+		t.Errorf("LatestID = %q, want 200", next.LatestID)
+	}
+}
+
+func TestMastodonTimeline_TagAndList(t *testing.T) {
+	var auth string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/api/v1/timelines/tag/golang", "/api/v1/timelines/list/42":
+			fmt.Fprint(w, `[]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	m := &Mastodon{Client: srv.Client()}
+	if _, _, err := m.Timeline(t.Context(), "#golang@"+host, store.Cursor{}); err != nil {
+		t.Errorf("tag timeline: %v", err)
+	}
+	if auth != "" {
+		t.Errorf("anonymous request sent Authorization %q", auth)
+	}
+	if _, _, err := m.Timeline(t.Context(), "list:42@"+host, store.Cursor{}); err == nil {
+		t.Error("list timeline without a token should fail")
+	}
+
+	m.Tokens = map[string]string{host: "secret"}
+	if _, _, err := m.Timeline(t.Context(), "list:42@"+host, store.Cursor{}); err != nil {
+		t.Errorf("list timeline: %v", err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+}
+
+func TestParseMastodonTarget(t *testing.T) {
+	tests := []struct {
+		input string
+		want  mastodonTarget
+	}{
+		{"@bob@social.example", mastodonTarget{"social.example", "account", "bob"}},
+		{"#golang@fosstodon.org", mastodonTarget{"fosstodon.org", "tag", "golang"}},
+		{"list:42@social.example", mastodonTarget{"social.example", "list", "42"}},
+	}
+	for _, tt := range tests {
+		got, err := parseMastodonTarget(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("parseMastodonTarget(%q) = %+v, %v, want %+v", tt.input, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"bob@social.example", "@bob", "#@host", "@bob@"} {
+		if _, err := parseMastodonTarget(bad); err == nil {
+			t.Errorf("parseMastodonTarget(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// Default link-aggregator API endpoints.
+const (
+	DefaultHNURL       = "https://hacker-news.firebaseio.com/v0"
+	DefaultLobstersURL = "https://lobste.rs"
+)
+
+const (
+	// hnMaxStories is how far down a Hacker News list we look — the
+	// first page of the site is 30 stories.
+	hnMaxStories = 30
+
+	// hnConcurrency bounds parallel item requests. The API serves one
+	// item per request, so a list costs hnMaxStories round trips.
+	hnConcurrency = 8
+)
+
+// hnLists maps the lists an hn: feed can follow to their API endpoints.
+var hnLists = map[string]string{
+	"top":  "topstories",
+	"best": "beststories",
+	"new":  "newstories",
+	"ask":  "askstories",
+	"show": "showstories",
+}
+
+// Thresholds are the minimum score and comment count a story needs to
+// appear in a feed.
+type Thresholds struct {
+	Points   int
+	Comments int
+}
+
+// allows reports whether a story with the given counts passes.
+func (t Thresholds) allows(points, comments int) bool {
+	return points >= t.Points && comments >= t.Comments
+}
+
+// HackerNews fetches stories from the Hacker News Firebase API.
+type HackerNews struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// hnItem mirrors the fields we use from an HN item.
+type hnItem struct {
+	ID          int    `json:"id"`
+	Type        string `json:"type"`
+	By          string `json:"by"`
+	Time        int64  `json:"time"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Text        string `json:"text"`
+	Score       int    `json:"score"`
+	Descendants int    `json:"descendants"`
+	Dead        bool   `json:"dead"`
+	Deleted     bool   `json:"deleted"`
+}
+
+// Stories fetches a Hacker News list (top, best, new, ask or show) and
+// returns the stories that meet the thresholds.
+//
+// WHY: There's no cursor. Every refresh re-reads the list and re-checks
+// scores, so a story that was below the threshold last time appears
+// once it crosses it; ones already shown are caught by dedup.
+func (hn *HackerNews) Stories(ctx context.Context, list string, want Thresholds) ([]model.Article, error) {
+	if list == "" {
+		list = "top"
+	}
+	endpoint, ok := hnLists[list]
+	if !ok {
+		return nil, fmt.Errorf("unknown Hacker News list %q (want top, best, new, ask or show)", list)
+	}
+	base := httpx.OrDefault(hn.BaseURL, DefaultHNURL)
+
+	var ids []int
+	if err := httpx.GetJSON(ctx, hn.Client, base+"/"+endpoint+".json", nil, &ids); err != nil {
+		return nil, fmt.Errorf("fetching Hacker News %s: %w", list, err)
+	}
+	ids = ids[:min(len(ids), hnMaxStories)]
+
+	// LEARN: Each goroutine writes only its own slot, so the slices
+	// need no lock and the list's ranking order is kept.
+	items := make([]hnItem, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, hnConcurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = httpx.GetJSON(ctx, hn.Client, fmt.Sprintf("%s/item/%d.json", base, id), nil, &items[i])
+		}()
+	}
+	wg.Wait()
+
+	// WHY: One item the API fails to serve shouldn't hide the other
+	// stories. Only when every item fails is the feed itself broken.
+	var articles []model.Article
+	var firstErr error
+	failed := 0
+	for i, item := range items {
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("fetching Hacker News item %d: %w", ids[i], errs[i])
+			}
+			failed++
+			continue
+		}
+		if item.Dead || item.Deleted || item.Type != "story" || !want.allows(item.Score, item.Descendants) {
+			continue
+		}
+		articles = append(articles, mapHNItem(item))
+	}
+	if failed > 0 {
+		if failed == len(ids) {
+			return nil, firstErr
+		}
+		log.Warn("Some Hacker News stories could not be fetched", "list", list, "count", failed, "error", firstErr)
+	}
+	return articles, nil
+}
+
+// mapHNItem converts a story to an Article. Text posts (Ask HN) have
+// no target URL, so their URL is the discussion itself.
+func mapHNItem(item hnItem) model.Article {
+	comments := fmt.Sprintf("https://news.ycombinator.com/item?id=%d", item.ID)
+	a := model.Article{
+		GUID:        fmt.Sprintf("hn:%d", item.ID),
+		Title:       item.Title,
+		URL:         item.URL,
+		CommentsURL: comments,
+		Author:      item.By,
+		Summary:     storyStats(item.Score, item.Descendants, nil),
+		Content:     item.Text,
+		PublishedAt: time.Unix(item.Time, 0),
+		FetchedAt:   time.Now(),
+	}
+	if a.URL == "" {
+		a.URL = comments
+	}
+	a.NormalizedTitle = NormalizeTitle(a.Title)
+	return a
+}
+
+// Lobsters fetches stories from a Lobsters instance's JSON API.
+type Lobsters struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// lobstersStory mirrors the fields we use from a Lobsters story.
+type lobstersStory struct {
+	ShortID      string          `json:"short_id"`
+	Title        string          `json:"title"`
+	URL          string          `json:"url"`
+	Score        int             `json:"score"`
+	CommentCount int             `json:"comment_count"`
+	CommentsURL  string          `json:"comments_url"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Description  string          `json:"description"`
+	Submitter    json.RawMessage `json:"submitter_user"`
+	Tags         []string        `json:"tags"`
+}
+
+// Stories fetches the hottest stories, the newest ones, or those with
+// the given tags ("go", "go,rust"), keeping the ones that meet the
+// thresholds. Like HackerNews.Stories it re-checks every refresh.
+func (l *Lobsters) Stories(ctx context.Context, target string, want Thresholds) ([]model.Article, error) {
+	base := httpx.OrDefault(l.BaseURL, DefaultLobstersURL)
+	var path string
+	switch target {
+	case "", "hottest":
+		path = "/hottest.json"
+	case "newest":
+		path = "/newest.json"
+	default:
+		path = "/t/" + url.PathEscape(target) + ".json"
+	}
+
+	var stories []lobstersStory
+	if err := httpx.GetJSON(ctx, l.Client, base+path, nil, &stories); err != nil {
+		return nil, fmt.Errorf("fetching Lobsters %s: %w", path, err)
+	}
+
+	var articles []model.Article
+	for _, s := range stories {
+		if !want.allows(s.Score, s.CommentCount) {
+			continue
+		}
+		a := model.Article{
+			GUID:        "lobsters:" + s.ShortID,
+			Title:       s.Title,
+			URL:         s.URL,
+			CommentsURL: s.CommentsURL,
+			Author:      lobstersUser(s.Submitter),
+			Summary:     storyStats(s.Score, s.CommentCount, s.Tags),
+			Content:     s.Description,
+			PublishedAt: s.CreatedAt,
+			FetchedAt:   time.Now(),
+		}
+		if a.URL == "" {
+			a.URL = s.CommentsURL
+		}
+		a.NormalizedTitle = NormalizeTitle(a.Title)
+		articles = append(articles, a)
+	}
+	return articles, nil
+}
+
+// lobstersUser reads submitter_user, which older servers send as an
+// object and newer ones as a bare username.
+func lobstersUser(raw json.RawMessage) string {
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		return name
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	json.Unmarshal(raw, &user)
+	return user.Username
+}
+
+// storyStats summarises a story's score, comments and tags.
+func storyStats(points, comments int, tags []string) string {
+	s := fmt.Sprintf("%d points · %d comments", points, comments)
+	if len(tags) > 0 {
+		s += " · " + strings.Join(tags, ", ")
+	}
+	return s
+}
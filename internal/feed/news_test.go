@@ -0,0 +1,128 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHackerNewsStories(t *testing.T) {
+	score := 40
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/topstories.json":
+			fmt.Fprint(w, `[1, 2, 3, 4, 5, 6]`) // 6 fails to load
+		case "/item/1.json":
+			fmt.Fprint(w, `{"id":1,"type":"story","by":"pg","time":1700000000,"title":"Show HN: A thing","url":"https://thing.example","score":250,"descendants":80}`)
+		case "/item/2.json":
+			fmt.Fprint(w, `{"id":2,"type":"story","by":"dang","time":1700000100,"title":"Ask HN: Favourite editor?","text":"<p>Curious.</p>","score":120,"descendants":300}`)
+		case "/item/3.json":
+			// Climbs between refreshes.
+			fmt.Fprintf(w, `{"id":3,"type":"story","by":"tptacek","time":1700000200,"title":"Rising story","url":"https://rising.example","score":%d,"descendants":20}`, score)
+		case "/item/4.json":
+			fmt.Fprint(w, `{"id":4,"type":"job","time":1700000300,"title":"We're hiring","score":500}`)
+		case "/item/5.json":
+			fmt.Fprint(w, `{"id":5,"type":"story","dead":true,"title":"Flagged","score":500,"descendants":50}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	hn := &HackerNews{BaseURL: srv.URL, Client: srv.Client()}
+	want := Thresholds{Points: 100, Comments: 10}
+
+	articles, err := hn.Stories(t.Context(), "top", want)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("got %d articles, want 2 above the thresholds", len(articles))
+	}
+
+	show := articles[0]
+	if show.URL != "https://thing.example" || show.CommentsURL != "https://news.ycombinator.com/item?id=1" {
+		t.Errorf("URL, CommentsURL = %q, %q", show.URL, show.CommentsURL)
+	}
+	if show.Summary != "250 points · 80 comments" || show.GUID != "hn:1" {
+		t.Errorf("Summary, GUID = %q, %q", show.Summary, show.GUID)
+	}
+	if ask := articles[1]; ask.URL != ask.CommentsURL || ask.Content != "<p>Curious.</p>" {
+		t.Errorf("Ask HN URL = %q, Content = %q", ask.URL, ask.Content)
+	}
+
+	// The rising story appears once it crosses the threshold.
+	score = 150
+	articles, err = hn.Stories(t.Context(), "top", want)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 3 || articles[2].GUID != "hn:3" {
+		t.Errorf("after rising, got %d articles; want hn:3 included", len(articles))
+	}
+
+	if _, err := hn.Stories(t.Context(), "jobs", want); err == nil {
+		t.Error("expected error for unknown list")
+	}
+}
+
+func TestHackerNewsStories_AllItemsFail(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/newstories.json" {
+			fmt.Fprint(w, `[1, 2]`)
+			return
+		}
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	hn := &HackerNews{BaseURL: srv.URL, Client: srv.Client()}
+
+	if _, err := hn.Stories(t.Context(), "new", Thresholds{}); err == nil {
+		t.Error("expected an error when no item could be fetched")
+	}
+}
+
+func TestLobstersStories(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		fmt.Fprint(w, `[
+  {"short_id":"abc123","title":"Go generics in practice","url":"https://blog.example/generics","score":25,"comment_count":12,
+   "comments_url":"https://lobste.rs/s/abc123/go_generics","created_at":"2024-05-01T10:00:00.000-05:00",
+   "submitter_user":"alice","tags":["go","programming"]},
+  {"short_id":"def456","title":"Meta thread","url":"","score":30,"comment_count":40,
+   "comments_url":"https://lobste.rs/s/def456/meta","created_at":"2024-05-02T10:00:00.000-05:00",
+   "submitter_user":{"username":"bob"},"tags":["meta"],"description":"<p>Discuss.</p>"},
+  {"short_id":"ghi789","title":"Quiet story","url":"https://quiet.example","score":3,"comment_count":0,
+   "comments_url":"https://lobste.rs/s/ghi789/quiet","created_at":"2024-05-03T10:00:00.000-05:00",
+   "submitter_user":"carol","tags":["go"]}
+]`)
+	}))
+	defer srv.Close()
+	l := &Lobsters{BaseURL: srv.URL, Client: srv.Client()}
+
+	articles, err := l.Stories(t.Context(), "go", Thresholds{Points: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/t/go.json" {
+		t.Errorf("requested %q, want /t/go.json", path)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("got %d articles, want 2", len(articles))
+	}
+	first := articles[0]
+	if first.URL != "https://blog.example/generics" || first.CommentsURL != "https://lobste.rs/s/abc123/go_generics" {
+		t.Errorf("URL, CommentsURL = %q, %q", first.URL, first.CommentsURL)
+	}
+	if first.Author != "alice" || first.Summary != "25 points · 12 comments · go, programming" {
+		t.Errorf("Author, Summary = %q, %q", first.Author, first.Summary)
+	}
+	if meta := articles[1]; meta.Author != "bob" || meta.URL != meta.CommentsURL {
+		t.Errorf("text story Author, URL = %q, %q", meta.Author, meta.URL)
+	}
+
+	if _, err := l.Stories(t.Context(), "", Thresholds{}); err != nil || path != "/hottest.json" {
+		t.Errorf("default list requested %q, err %v", path, err)
+	}
+}
@@ -0,0 +1,386 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/release"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+// Docker Hub is addressed as docker.io but served from another host.
+const (
+	dockerHubHost     = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+// maxTagListPages bounds how far the tag list is paged for repos with
+// thousands of tags (nightly builds, per-commit tags).
+const maxTagListPages = 10
+
+// Media types accepted when fetching a manifest. Listing the index types
+// lets multi-platform images answer with their index.
+var manifestMediaTypes = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+// errUnauthorized marks a 401 whose challenge has already been answered.
+var errUnauthorized = errors.New("unauthorized")
+
+// OCI fetches image tags from registries speaking the OCI distribution
+// API (Docker Hub, GHCR, Quay, self-hosted registries).
+type OCI struct {
+	Client *http.Client
+}
+
+// ociRef is a parsed "registry/repository" image reference.
+type ociRef struct {
+	host, repo string
+}
+
+// parseOCIRef parses an image reference the way docker does: a first
+// segment with a dot or port is a registry, otherwise it's Docker Hub,
+// where single-segment names live under "library/".
+func parseOCIRef(ref string) (ociRef, error) {
+	ref = strings.Trim(ref, "/")
+	host, repo, ok := strings.Cut(ref, "/")
+	if !ok || !strings.ContainsAny(host, ".:") && host != "localhost" {
+		host, repo = dockerHubHost, ref
+	}
+	if repo == "" {
+		return ociRef{}, fmt.Errorf("invalid image reference %q", ref)
+	}
+	if host == dockerHubHost && !strings.Contains(repo, "/") {
+		repo = "library/" + repo
+	}
+	return ociRef{host: host, repo: repo}, nil
+}
+
+// apiHost returns the host serving the registry API.
+func (r ociRef) apiHost() string {
+	if r.host == dockerHubHost {
+		return dockerHubRegistry
+	}
+	return r.host
+}
+
+// webURL links to a tag on the registry's website where one exists.
+func (r ociRef) webURL(tag string) string {
+	switch r.host {
+	case dockerHubHost:
+		if name, ok := strings.CutPrefix(r.repo, "library/"); ok {
+			return "https://hub.docker.com/_/" + name + "/tags?name=" + url.QueryEscape(tag)
+		}
+		return "https://hub.docker.com/r/" + r.repo + "/tags?name=" + url.QueryEscape(tag)
+	case "quay.io":
+		return "https://quay.io/repository/" + r.repo + "?tab=tags"
+	}
+	return "https://" + r.host + "/" + r.repo
+}
+
+// ociSession carries one refresh's bearer token between requests, so
+// the token is fetched once per repository rather than per request.
+type ociSession struct {
+	client *http.Client
+	ref    ociRef
+	token  string
+}
+
+// Tags lists an image's tags and returns one Article per version tag
+// missing from the cursor's Seen set, dated by the image config's
+// creation time.
+// Tags the filter rejects are dropped before any manifest is fetched.
+func (o *OCI) Tags(ctx context.Context, image string, cursor store.Cursor, filter *release.Filter) ([]model.Article, store.Cursor, error) {
+	ref, err := parseOCIRef(image)
+	if err != nil {
+		return nil, cursor, err
+	}
+	s := &ociSession{client: httpx.Client(o.Client), ref: ref}
+
+	all, err := s.listTags(ctx)
+	if err != nil {
+		return nil, cursor, fmt.Errorf("listing tags for %s: %w", image, err)
+	}
+
+	// WHY: Tag lists come back in lexical order with moving tags like
+	// "latest" mixed in. Only tags with a version are releases, and
+	// ordering them puts the newest first when maxNewTags cuts in.
+	type versioned struct {
+		tag string
+		v   release.Version
+	}
+	var tags []versioned
+	for _, tag := range all {
+		v, ok := release.Parse(tag)
+		if !ok || (filter != nil && !filter.Allows(tag, false)) {
+			continue
+		}
+		tags = append(tags, versioned{tag, v})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return release.Compare(tags[i].v, tags[j].v) > 0 })
+
+	// WHY: Patch releases of older lines ("1.24.9" after "1.25.3") and
+	// new variants of a version ("1.25.3-bookworm") don't sort after the
+	// newest tag seen, so new tags are the ones missing from Seen.
+	seen := seenBefore(cursor)
+	var articles []model.Article
+	for _, t := range tags {
+		if len(articles) == maxNewTags {
+			break
+		}
+		if seen(t.tag) {
+			continue
+		}
+		a, err := s.tagArticle(ctx, t.tag)
+		if err != nil {
+			return nil, cursor, fmt.Errorf("fetching %s:%s: %w", image, t.tag, err)
+		}
+		articles = append(articles, a)
+	}
+	return articles, store.Cursor{Seen: seenSet(all)}, nil
+}
+
+// listTags pages through /v2/<repo>/tags/list.
+func (s *ociSession) listTags(ctx context.Context) ([]string, error) {
+	var tags []string
+	next := fmt.Sprintf("https://%s/v2/%s/tags/list?n=1000", s.ref.apiHost(), s.ref.repo)
+	for page := 0; next != "" && page < maxTagListPages; page++ {
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+		resp, err := s.getJSON(ctx, next, "application/json", &list)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, list.Tags...)
+		next = nextLink(resp, next)
+	}
+	return tags, nil
+}
+
+// tagArticle resolves a tag to its image config and maps it.
+func (s *ociSession) tagArticle(ctx context.Context, tag string) (model.Article, error) {
+	base := fmt.Sprintf("https://%s/v2/%s", s.ref.apiHost(), s.ref.repo)
+
+	var manifest struct {
+		MediaType string `json:"mediaType"`
+		Config    struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+		Manifests []struct {
+			Digest   string `json:"digest"`
+			Platform struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	resp, err := s.getJSON(ctx, base+"/manifests/"+url.PathEscape(tag), manifestMediaTypes, &manifest)
+	if err != nil {
+		return model.Article{}, err
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+
+	var platforms []string
+	if len(manifest.Manifests) > 0 {
+		// WHY: A multi-platform index has no config of its own. Any
+		// platform's config carries the build date; prefer linux/amd64
+		// as the one most likely to exist.
+		pick := manifest.Manifests[0].Digest
+		for _, m := range manifest.Manifests {
+			if m.Platform.OS == "" || m.Platform.OS == "unknown" {
+				continue // attestation manifests
+			}
+			platforms = append(platforms, m.Platform.OS+"/"+m.Platform.Architecture)
+			if m.Platform.OS == "linux" && m.Platform.Architecture == "amd64" {
+				pick = m.Digest
+			}
+		}
+		if _, err := s.getJSON(ctx, base+"/manifests/"+pick, manifestMediaTypes, &manifest); err != nil {
+			return model.Article{}, err
+		}
+	}
+
+	var config struct {
+		Created string `json:"created"`
+	}
+	if manifest.Config.Digest != "" {
+		if _, err := s.getJSON(ctx, base+"/blobs/"+manifest.Config.Digest, "", &config); err != nil {
+			return model.Article{}, err
+		}
+	}
+
+	info := releaseInfo{
+		guid: fmt.Sprintf("oci:%s/%s:%s", s.ref.host, s.ref.repo, tag),
+		repo: s.ref.host + "/" + s.ref.repo,
+		tag:  tag,
+		url:  s.ref.webURL(tag),
+		body: ociBody(digest, platforms),
+	}
+	// LEARN: RFC3339Nano also accepts timestamps without fractional
+	// seconds, so it covers both shapes builders write.
+	info.publishedAt, _ = time.Parse(time.RFC3339Nano, config.Created)
+	return info.article(), nil
+}
+
+// ociBody describes an image for the article content.
+func ociBody(digest string, platforms []string) string {
+	var b strings.Builder
+	if digest != "" {
+		fmt.Fprintf(&b, "Digest: %s\n", digest)
+	}
+	if len(platforms) > 0 {
+		fmt.Fprintf(&b, "Platforms: %s\n", strings.Join(platforms, ", "))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// getJSON GETs a registry URL, answering a Bearer challenge once.
+func (s *ociSession) getJSON(ctx context.Context, u, accept string, v any) (*http.Response, error) {
+	resp, err := s.do(ctx, u, accept)
+	if errors.Is(err, errUnauthorized) && s.token == "" {
+		if err := s.authenticate(ctx, resp.Header.Get("WWW-Authenticate")); err != nil {
+			return nil, err
+		}
+		resp, err = s.do(ctx, u, accept)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", u, err)
+	}
+	return resp, nil
+}
+
+// do performs one request. On 401 it returns the response (with its
+// body closed) alongside errUnauthorized so the caller can read the
+// challenge.
+func (s *ociSession) do(ctx context.Context, u, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", httpx.UserAgent)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return resp, errUnauthorized
+	}
+	return nil, fmt.Errorf("%s: %s: %s", u, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// authenticate fetches an anonymous pull token from the realm named in
+// a `Bearer realm="...",service="...",scope="..."` challenge.
+func (s *ociSession) authenticate(ctx context.Context, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return fmt.Errorf("registry requires %q authentication, which isn't supported", scheme)
+	}
+	p := parseChallenge(params)
+	if p["realm"] == "" {
+		return fmt.Errorf("bearer challenge without realm: %q", challenge)
+	}
+
+	q := url.Values{}
+	if svc := p["service"]; svc != "" {
+		q.Set("service", svc)
+	}
+	scope := p["scope"]
+	if scope == "" {
+		scope = "repository:" + s.ref.repo + ":pull"
+	}
+	q.Set("scope", scope)
+
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := httpx.GetJSON(ctx, s.client, p["realm"]+"?"+q.Encode(), nil, &tok); err != nil {
+		return fmt.Errorf("fetching registry token: %w", err)
+	}
+	// LEARN: The distribution spec names the field "token"; OAuth2-style
+	// servers send "access_token". Accept whichever is present.
+	s.token = tok.Token
+	if s.token == "" {
+		s.token = tok.AccessToken
+	}
+	if s.token == "" {
+		return fmt.Errorf("registry token response had no token")
+	}
+	return nil
+}
+
+// parseChallenge splits `key="value",key2="value2"` auth parameters.
+// Values may contain commas (scopes do), so split on quotes, not commas.
+func parseChallenge(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.TrimSpace(strings.TrimLeft(key, ", "))
+		var val string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				val, rest = rest[1:], ""
+			} else {
+				val, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			val, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(key)] = val
+		s = rest
+	}
+	return params
+}
+
+// nextLink resolves an RFC 5988 `Link: <url>; rel="next"` header
+// against the current URL, or returns "" if there is none.
+func nextLink(resp *http.Response, current string) string {
+	link := resp.Header.Get("Link")
+	start, end := strings.IndexByte(link, '<'), strings.IndexByte(link, '>')
+	if start < 0 || end < start || !strings.Contains(link, `rel="next"`) {
+		return ""
+	}
+	base, err := url.Parse(current)
+	if err != nil {
+		return ""
+	}
+	next, err := base.Parse(link[start+1 : end])
+	if err != nil {
+		return ""
+	}
+	return next.String()
+}
@@ -0,0 +1,180 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/release"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+// fakeRegistry is a minimal distribution API that demands an anonymous
+// bearer token, the way Docker Hub and GHCR do.
+func fakeRegistry(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+	manifestFetches := 0
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if got := r.URL.Query().Get("scope"); got != "repository:team/app:pull" {
+				t.Errorf("token scope = %q", got)
+			}
+			fmt.Fprint(w, `{"token":"t0k"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t0k" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="repository:team/app:pull"`, srv.URL))
+			http.Error(w, `{"errors":[{"code":"UNAUTHORIZED"}]}`, http.StatusUnauthorized)
+			return
+		}
+
+		switch path := strings.TrimPrefix(r.URL.Path, "/v2/team/app/"); {
+		case path == "tags/list" && r.URL.Query().Get("last") == "":
+			w.Header().Set("Link", `</v2/team/app/tags/list?last=latest&n=1000>; rel="next"`)
+			fmt.Fprint(w, `{"name":"team/app","tags":["1.0.0","1.1.0","1.1.0-alpine","latest"]}`)
+		case path == "tags/list":
+			fmt.Fprint(w, `{"name":"team/app","tags":["1.2.0","2.0.0-rc.1","nightly"]}`)
+		case path == "manifests/1.2.0":
+			manifestFetches++
+			if !strings.Contains(r.Header.Get("Accept"), "image.index") {
+				t.Error("manifest request doesn't accept an index")
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:index120")
+			fmt.Fprint(w, `{"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[
+				{"digest":"sha256:arm","platform":{"os":"linux","architecture":"arm64"}},
+				{"digest":"sha256:amd","platform":{"os":"linux","architecture":"amd64"}},
+				{"digest":"sha256:att","platform":{"os":"unknown","architecture":"unknown"}}]}`)
+		case path == "manifests/sha256:amd":
+			fmt.Fprint(w, `{"config":{"digest":"sha256:cfg-1.2.0"}}`)
+		case strings.HasPrefix(path, "manifests/"):
+			manifestFetches++
+			tag := strings.TrimPrefix(path, "manifests/")
+			fmt.Fprintf(w, `{"config":{"digest":"sha256:cfg-%s"}}`, tag)
+		case strings.HasPrefix(path, "blobs/sha256:cfg-"):
+			created := map[string]string{
+				"1.0.0":        "2024-01-01T00:00:00Z",
+				"1.1.0":        "2024-02-01T00:00:00Z",
+				"1.1.0-alpine": "2024-02-01T00:00:00Z",
+				"1.2.0":        "2024-03-01T10:30:00.123456789Z",
+				"2.0.0-rc.1":   "2024-04-01T00:00:00Z",
+			}[strings.TrimPrefix(path, "blobs/sha256:cfg-")]
+			fmt.Fprintf(w, `{"created":%q,"architecture":"amd64"}`, created)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &manifestFetches
+}
+
+func TestOCITags(t *testing.T) {
+	srv, _ := fakeRegistry(t)
+	o := &OCI{Client: srv.Client()}
+	image := strings.TrimPrefix(srv.URL, "https://") + "/team/app"
+
+	articles, cursor, err := o.Tags(t.Context(), image, store.Cursor{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 5 {
+		t.Fatalf("got %d articles, want 5 version tags", len(articles))
+	}
+	if len(cursor.Seen) != 7 {
+		t.Errorf("Seen = %v, want every listed tag", cursor.Seen)
+	}
+
+	multi := articles[1]
+	if multi.Version != "1.2.0" || multi.GUID != "oci:"+image+":1.2.0" {
+		t.Errorf("article = %q / %q", multi.Version, multi.GUID)
+	}
+	if got := multi.PublishedAt.Format("2006-01-02 15:04"); got != "2024-03-01 10:30" {
+		t.Errorf("PublishedAt = %s, want the amd64 config's created date", got)
+	}
+	if !strings.Contains(multi.Content, "sha256:index120") || !strings.Contains(multi.Content, "linux/arm64, linux/amd64") {
+		t.Errorf("Content = %q", multi.Content)
+	}
+	if strings.Contains(multi.Content, "unknown") {
+		t.Error("attestation manifest listed as a platform")
+	}
+}
+
+func TestOCITags_FilterAndCursor(t *testing.T) {
+	srv, fetches := fakeRegistry(t)
+	o := &OCI{Client: srv.Client()}
+	image := strings.TrimPrefix(srv.URL, "https://") + "/team/app"
+
+	filter, err := release.NewFilter(model.Feed{TagRegex: `^1\.`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	articles, cursor, err := o.Tags(t.Context(), image, store.Cursor{LatestID: "1.0.0"}, filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, a := range articles {
+		got = append(got, a.Version)
+	}
+	if want := []string{"1.2.0", "1.1.0", "1.1.0-alpine"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("articles = %v, want %v", got, want)
+	}
+	if *fetches != 3 {
+		t.Errorf("fetched %d manifests, want 3 — filtered tags shouldn't be fetched", *fetches)
+	}
+
+	// A tag pushed for an older line is new even though a newer version
+	// was seen, and a variant tag isn't taken for a prerelease.
+	delete(cursor.Seen, "1.1.0-alpine")
+	delete(cursor.Seen, "1.0.0")
+	articles, _, err = o.Tags(t.Context(), image, cursor, filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got = nil
+	for _, a := range articles {
+		got = append(got, a.Version)
+	}
+	if want := []string{"1.1.0-alpine", "1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("articles = %v, want %v", got, want)
+	}
+}
+
+func TestParseOCIRef(t *testing.T) {
+	tests := []struct {
+		input    string
+		host     string
+		repo     string
+		apiHost  string
+		webURLTo string
+	}{
+		{"nginx", "docker.io", "library/nginx", "registry-1.docker.io", "https://hub.docker.com/_/nginx/tags?name=1.0"},
+		{"grafana/grafana", "docker.io", "grafana/grafana", "registry-1.docker.io", "https://hub.docker.com/r/grafana/grafana/tags?name=1.0"},
+		{"ghcr.io/owner/app", "ghcr.io", "owner/app", "ghcr.io", "https://ghcr.io/owner/app"},
+		{"localhost:5000/app", "localhost:5000", "app", "localhost:5000", "https://localhost:5000/app"},
+	}
+	for _, tt := range tests {
+		ref, err := parseOCIRef(tt.input)
+		if err != nil {
+			t.Errorf("parseOCIRef(%q): %v", tt.input, err)
+			continue
+		}
+		if ref.host != tt.host || ref.repo != tt.repo || ref.apiHost() != tt.apiHost {
+			t.Errorf("parseOCIRef(%q) = %+v (api %s)", tt.input, ref, ref.apiHost())
+		}
+		if got := ref.webURL("1.0"); got != tt.webURLTo {
+			t.Errorf("webURL(%q) = %q, want %q", tt.input, got, tt.webURLTo)
+		}
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	got := parseChallenge(`realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:a/b:pull,push"`)
+	if got["realm"] != "https://auth.docker.io/token" || got["service"] != "registry.docker.io" || got["scope"] != "repository:a/b:pull,push" {
+		t.Errorf("parseChallenge = %v", got)
+	}
+}
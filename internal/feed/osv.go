@@ -0,0 +1,316 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/deps"
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+// DefaultOSVURL is the OSV API used when none is configured.
+const DefaultOSVURL = "https://api.osv.dev"
+
+// osvBatchSize is the most queries the OSV API accepts in one
+// /v1/querybatch request.
+const osvBatchSize = 1000
+
+// maxOSVPages bounds how many pages of results are read for one batch,
+// in case a server keeps handing out page tokens.
+const maxOSVPages = 100
+
+// OSV fetches vulnerability advisories from an OSV-compatible API.
+type OSV struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// osvVuln mirrors the fields we use from the OSV schema.
+type osvVuln struct {
+	ID        string    `json:"id"`
+	Summary   string    `json:"summary"`
+	Details   string    `json:"details"`
+	Aliases   []string  `json:"aliases"`
+	Modified  time.Time `json:"modified"`
+	Published time.Time `json:"published"`
+	Severity  []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string `json:"type"`
+			Events []struct {
+				Introduced   string `json:"introduced,omitempty"`
+				Fixed        string `json:"fixed,omitempty"`
+				LastAffected string `json:"last_affected,omitempty"`
+			} `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// Advisories queries advisories affecting the packages named by target,
+// which is either a manifest path (go.mod, package.json, Cargo.toml) or
+// a comma-separated list of "ecosystem:name[@version]".
+//
+// The cursor's Seen holds each reported advisory's modification time,
+// so later refreshes only download advisories that are new to the feed
+// or changed since.
+func (o *OSV) Advisories(ctx context.Context, target string, cursor store.Cursor) ([]model.Article, store.Cursor, error) {
+	pkgs, err := osvPackages(target)
+	if err != nil {
+		return nil, cursor, err
+	}
+	base := httpx.OrDefault(o.BaseURL, DefaultOSVURL)
+
+	// WHY: querybatch only returns IDs and modification times, which is
+	// exactly enough to decide which full records to download.
+	type query struct {
+		Package struct {
+			Name      string `json:"name"`
+			Ecosystem string `json:"ecosystem"`
+		} `json:"package"`
+		Version   string `json:"version,omitempty"`
+		PageToken string `json:"page_token,omitempty"`
+	}
+	modified := make(map[string]time.Time)
+	var ids []string
+	for batch := range slices.Chunk(pkgs, osvBatchSize) {
+		var queries []query
+		for _, p := range batch {
+			var q query
+			q.Package.Name, q.Package.Ecosystem, q.Version = p.Name, p.Ecosystem, p.Version
+			queries = append(queries, q)
+		}
+
+		// WHY: OSV pages each query's results separately. Queries that
+		// come back with a next_page_token are sent again with it, until
+		// none do.
+		for page := 0; len(queries) > 0; page++ {
+			if page == maxOSVPages {
+				return nil, cursor, fmt.Errorf("querying OSV: more than %d pages of results", maxOSVPages)
+			}
+			req := struct {
+				Queries []query `json:"queries"`
+			}{queries}
+			var resp struct {
+				Results []struct {
+					Vulns []struct {
+						ID       string    `json:"id"`
+						Modified time.Time `json:"modified"`
+					} `json:"vulns"`
+					NextPageToken string `json:"next_page_token"`
+				} `json:"results"`
+			}
+			if err := httpx.PostJSON(ctx, o.Client, base+"/v1/querybatch", nil, req, &resp); err != nil {
+				return nil, cursor, fmt.Errorf("querying OSV: %w", err)
+			}
+
+			var more []query
+			for i, r := range resp.Results {
+				for _, v := range r.Vulns {
+					if _, seen := modified[v.ID]; !seen {
+						ids = append(ids, v.ID)
+					}
+					modified[v.ID] = v.Modified
+				}
+				if r.NextPageToken != "" && i < len(queries) {
+					q := queries[i]
+					q.PageToken = r.NextPageToken
+					more = append(more, q)
+				}
+			}
+			queries = more
+		}
+	}
+
+	// WHY: A dependency added to the manifest brings advisories that
+	// were modified long ago, so only the set of advisories already
+	// reported can tell which ones are new to this feed.
+	next := store.Cursor{Seen: make(map[string]string, len(ids))}
+	now := time.Now()
+	var articles []model.Article
+	for _, id := range ids {
+		stamp := modified[id].UTC().Format(time.RFC3339Nano)
+		next.Seen[id] = stamp
+		if osvSeen(cursor, id, modified[id]) {
+			continue
+		}
+		var vuln osvVuln
+		if err := httpx.GetJSON(ctx, o.Client, base+"/v1/vulns/"+id, nil, &vuln); err != nil {
+			return nil, cursor, fmt.Errorf("fetching advisory %s: %w", id, err)
+		}
+		articles = append(articles, mapAdvisory(vuln, pkgs, now))
+	}
+	return articles, next, nil
+}
+
+// osvSeen reports whether the advisory was already reported by an
+// earlier refresh and hasn't been modified since.
+func osvSeen(cursor store.Cursor, id string, modified time.Time) bool {
+	if cursor.Seen == nil {
+		// Cursors from before Seen was kept hold only the newest
+		// modification time.
+		return !cursor.Since.IsZero() && !modified.After(cursor.Since)
+	}
+	stamp, ok := cursor.Seen[id]
+	if !ok {
+		return false
+	}
+	prev, err := time.Parse(time.RFC3339Nano, stamp)
+	return err == nil && !modified.After(prev)
+}
+
+// osvPackages resolves a target to the packages to query.
+func osvPackages(target string) ([]deps.Dependency, error) {
+	switch filepath.Base(target) {
+	case "go.mod", "package.json", "Cargo.toml":
+		// WHY: Indirect requirements are compiled in like direct ones,
+		// and transitive modules are where most advisories turn up.
+		m, err := deps.ParseFileWith(target, deps.ParseOptions{Indirect: true})
+		if err != nil {
+			return nil, err
+		}
+		for i, d := range m.Deps {
+			// WHY: OSV matches exact versions only. Go requirements are
+			// exact; npm and Cargo ones are ranges ("^1.2"), so those
+			// query every advisory for the package instead.
+			if d.Ecosystem != deps.Go {
+				m.Deps[i].Version = ""
+			}
+		}
+		return m.Deps, nil
+	}
+
+	var pkgs []deps.Dependency
+	for _, item := range strings.Split(target, ",") {
+		eco, name, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok || eco == "" || name == "" {
+			return nil, fmt.Errorf("invalid OSV package %q, expected ecosystem:name[@version]", item)
+		}
+		var version string
+		// LEARN: LastIndex skips the '@' that starts a scoped npm name.
+		if i := strings.LastIndex(name, "@"); i > 0 {
+			name, version = name[:i], name[i+1:]
+		}
+		pkgs = append(pkgs, deps.Dependency{Ecosystem: eco, Name: name, Version: version})
+	}
+	return pkgs, nil
+}
+
+// mapAdvisory converts an advisory found at now to an Article,
+// describing only the affected packages that were queried.
+func mapAdvisory(v osvVuln, queried []deps.Dependency, now time.Time) model.Article {
+	var affected []string
+	var fixed []string
+	for _, a := range v.Affected {
+		if !slices.ContainsFunc(queried, func(d deps.Dependency) bool {
+			return d.Ecosystem == a.Package.Ecosystem && d.Name == a.Package.Name
+		}) {
+			continue
+		}
+		var ranges []string
+		for _, r := range a.Ranges {
+			if r.Type == "GIT" {
+				continue // commit hashes mean nothing to a reader
+			}
+			var lo string
+			for _, e := range r.Events {
+				switch {
+				case e.Introduced != "":
+					lo = e.Introduced
+				case e.Fixed != "":
+					ranges = append(ranges, versionRange(lo, "<"+e.Fixed))
+					fixed = append(fixed, a.Package.Name+" "+e.Fixed)
+					lo = ""
+				case e.LastAffected != "":
+					ranges = append(ranges, versionRange(lo, "<="+e.LastAffected))
+					lo = ""
+				}
+			}
+			if lo != "" {
+				ranges = append(ranges, versionRange(lo, ""))
+			}
+		}
+		affected = append(affected, fmt.Sprintf("%s (%s): %s", a.Package.Name, a.Package.Ecosystem, strings.Join(ranges, "; ")))
+	}
+
+	severity := v.DatabaseSpecific.Severity
+	if severity == "" && len(v.Severity) > 0 {
+		severity = v.Severity[0].Score
+	}
+
+	var b strings.Builder
+	if severity != "" {
+		fmt.Fprintf(&b, "**Severity:** %s\n\n", severity)
+	}
+	if len(v.Aliases) > 0 {
+		fmt.Fprintf(&b, "**Aliases:** %s\n\n", strings.Join(v.Aliases, ", "))
+	}
+	if !v.Published.IsZero() {
+		fmt.Fprintf(&b, "**Published:** %s\n\n", v.Published.Format(time.DateOnly))
+	}
+	for _, a := range affected {
+		fmt.Fprintf(&b, "**Affected:** %s\n\n", a)
+	}
+	if len(fixed) > 0 {
+		fmt.Fprintf(&b, "**Fixed in:** %s\n\n", strings.Join(fixed, ", "))
+	} else {
+		b.WriteString("**Fixed in:** no fixed version yet\n\n")
+	}
+	b.WriteString(v.Details)
+
+	title := v.ID
+	if v.Summary != "" {
+		title += ": " + v.Summary
+	}
+	summary := "No fix available"
+	if len(fixed) > 0 {
+		summary = "Fixed in " + strings.Join(fixed, ", ")
+	}
+	if severity != "" && v.DatabaseSpecific.Severity != "" {
+		summary = severity + " · " + summary
+	}
+
+	// WHY: An advisory is news when it first affects one of the feed's
+	// packages, which may be years after it was published. Dated by
+	// Published, retention would expire it on arrival.
+	return model.Article{
+		GUID:        "osv:" + v.ID,
+		Title:       title,
+		URL:         "https://osv.dev/vulnerability/" + v.ID,
+		Summary:     summary,
+		Content:     strings.TrimSpace(b.String()),
+		PublishedAt: now,
+		FetchedAt:   now,
+	}
+}
+
+// versionRange renders an introduced version and an upper bound such as
+// "<1.2.3" as a readable range.
+func versionRange(introduced, upper string) string {
+	switch {
+	case introduced == "" || introduced == "0":
+		if upper == "" {
+			return "all versions"
+		}
+		return upper
+	case upper == "":
+		return ">=" + introduced
+	}
+	return ">=" + introduced + ", " + upper
+}
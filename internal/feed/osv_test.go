@@ -0,0 +1,230 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/deps"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+const osvVulnJSON = `{
+  "id": "GO-2024-2687",
+  "summary": "HTTP/2 CONTINUATION flood in net/http",
+  "details": "An attacker may cause an HTTP/2 endpoint to read arbitrary amounts of header data.",
+  "aliases": ["CVE-2023-45288", "GHSA-4v7x-pqxf-cx7m"],
+  "modified": "2024-04-05T00:00:00Z",
+  "published": "2024-04-04T21:30:00Z",
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"}],
+  "affected": [
+    {"package": {"ecosystem": "Go", "name": "golang.org/x/net"},
+     "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.23.0"}]}]},
+    {"package": {"ecosystem": "Go", "name": "stdlib"},
+     "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.21.9"}, {"introduced": "1.22.0"}, {"fixed": "1.22.2"}]}]}
+  ]
+}`
+
+// fakeOSV serves one advisory for golang.org/x/net and two pages of
+// advisories for golang.org/x/crypto, recording the records downloaded.
+func fakeOSV(t *testing.T) (*OSV, *[]string) {
+	t.Helper()
+	var fetched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			if r.Method != http.MethodPost {
+				t.Errorf("querybatch method = %s", r.Method)
+			}
+			var req struct {
+				Queries []struct {
+					Package   struct{ Name, Ecosystem string }
+					Version   string
+					PageToken string `json:"page_token"`
+				}
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatal(err)
+			}
+			var results []string
+			for _, q := range req.Queries {
+				switch {
+				case q.Package.Name == "golang.org/x/net" && q.Version == "v0.20.0":
+					results = append(results, `{"vulns":[{"id":"GO-2024-2687","modified":"2024-04-05T00:00:00Z"}]}`)
+				case q.Package.Name == "golang.org/x/crypto" && q.PageToken == "":
+					results = append(results, `{"vulns":[{"id":"GO-2023-2402","modified":"2023-12-18T00:00:00Z"}],"next_page_token":"page-2"}`)
+				case q.Package.Name == "golang.org/x/crypto" && q.PageToken == "page-2":
+					results = append(results, `{"vulns":[{"id":"GO-2022-1144","modified":"2022-12-08T00:00:00Z"}]}`)
+				default:
+					results = append(results, `{}`)
+				}
+			}
+			fmt.Fprintf(w, `{"results":[%s]}`, strings.Join(results, ","))
+		case "/v1/vulns/GO-2024-2687":
+			fetched = append(fetched, "GO-2024-2687")
+			fmt.Fprint(w, osvVulnJSON)
+		case "/v1/vulns/GO-2023-2402", "/v1/vulns/GO-2022-1144":
+			id := strings.TrimPrefix(r.URL.Path, "/v1/vulns/")
+			fetched = append(fetched, id)
+			fmt.Fprintf(w, `{"id":%q,"summary":"x/crypto advisory","modified":"2023-01-01T00:00:00Z",
+				"affected":[{"package":{"ecosystem":"Go","name":"golang.org/x/crypto"}}]}`, id)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return &OSV{BaseURL: srv.URL, Client: srv.Client()}, &fetched
+}
+
+func TestOSVAdvisories_GoMod(t *testing.T) {
+	o, fetched := fakeOSV(t)
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	content := "module example.com/app\n\nrequire (\n\tgolang.org/x/net v0.20.0\n\tgolang.org/x/text v0.14.0\n)\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	articles, cursor, err := o.Advisories(t.Context(), gomod, store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 {
+		t.Fatalf("got %d articles, want 1", len(articles))
+	}
+	a := articles[0]
+	if a.GUID != "osv:GO-2024-2687" || a.URL != "https://osv.dev/vulnerability/GO-2024-2687" {
+		t.Errorf("GUID, URL = %q, %q", a.GUID, a.URL)
+	}
+	if a.Title != "GO-2024-2687: HTTP/2 CONTINUATION flood in net/http" {
+		t.Errorf("Title = %q", a.Title)
+	}
+	if a.Summary != "Fixed in golang.org/x/net 0.23.0" {
+		t.Errorf("Summary = %q", a.Summary)
+	}
+	for _, want := range []string{
+		"**Severity:** CVSS:3.1/",
+		"**Aliases:** CVE-2023-45288, GHSA-4v7x-pqxf-cx7m",
+		"**Affected:** golang.org/x/net (Go): <0.23.0",
+		"arbitrary amounts of header data",
+	} {
+		if !strings.Contains(a.Content, want) {
+			t.Errorf("Content missing %q:\n%s", want, a.Content)
+		}
+	}
+	if strings.Contains(a.Content, "stdlib") {
+		t.Error("Content describes a package that wasn't queried")
+	}
+	if a.NormalizedTitle != "" {
+		t.Error("advisories should opt out of fuzzy dedup")
+	}
+
+	// An unchanged advisory isn't downloaded again.
+	if _, _, err := o.Advisories(t.Context(), gomod, cursor); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*fetched) != 1 {
+		t.Errorf("advisory downloaded %d times, want 1", len(*fetched))
+	}
+	if got := cursor.Seen["GO-2024-2687"]; got != "2024-04-05T00:00:00Z" {
+		t.Errorf("Seen[GO-2024-2687] = %q, want its modification time", got)
+	}
+	if time.Since(a.PublishedAt) > time.Minute {
+		t.Errorf("PublishedAt = %v, want the fetch time so retention keeps it", a.PublishedAt)
+	}
+}
+
+func TestOSVAdvisories_AddedDependency(t *testing.T) {
+	o, _ := fakeOSV(t)
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(gomod, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("module example.com/app\n\nrequire golang.org/x/net v0.20.0\n")
+	_, cursor, err := o.Advisories(t.Context(), gomod, store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The added module's advisories are older than every one reported
+	// so far, and still new to the feed.
+	write("module example.com/app\n\nrequire (\n\tgolang.org/x/net v0.20.0\n\tgolang.org/x/crypto v0.1.0\n)\n")
+	articles, _, err := o.Advisories(t.Context(), gomod, cursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, a := range articles {
+		got = append(got, a.GUID)
+	}
+	want := []string{"osv:GO-2023-2402", "osv:GO-2022-1144"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("advisories = %v, want %v", got, want)
+	}
+}
+
+func TestOSVAdvisories_IndirectAndPaged(t *testing.T) {
+	o, _ := fakeOSV(t)
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	content := "module example.com/app\n\nrequire (\n\tgolang.org/x/text v0.14.0\n\tgolang.org/x/crypto v0.1.0 // indirect\n)\n"
+	if err := os.WriteFile(gomod, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	articles, _, err := o.Advisories(t.Context(), gomod, store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, a := range articles {
+		got = append(got, a.GUID)
+	}
+	want := []string{"osv:GO-2023-2402", "osv:GO-2022-1144"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("advisories = %v, want both pages for the indirect module: %v", got, want)
+	}
+}
+
+func TestOSVPackages_List(t *testing.T) {
+	got, err := osvPackages("Go:golang.org/x/net@v0.20.0, npm:@babel/core, PyPI:requests")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []deps.Dependency{
+		{Ecosystem: "Go", Name: "golang.org/x/net", Version: "v0.20.0"},
+		{Ecosystem: "npm", Name: "@babel/core"},
+		{Ecosystem: "PyPI", Name: "requests"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("osvPackages = %+v, want %+v", got, want)
+	}
+
+	if _, err := osvPackages("lodash"); err == nil {
+		t.Error("expected error for package without ecosystem")
+	}
+}
+
+func TestVersionRange(t *testing.T) {
+	tests := []struct {
+		introduced, upper, want string
+	}{
+		{"0", "<1.2.3", "<1.2.3"},
+		{"1.0.0", "<1.2.3", ">=1.0.0, <1.2.3"},
+		{"1.0.0", "", ">=1.0.0"},
+		{"", "", "all versions"},
+	}
+	for _, tt := range tests {
+		if got := versionRange(tt.introduced, tt.upper); got != tt.want {
+			t.Errorf("versionRange(%q, %q) = %q, want %q", tt.introduced, tt.upper, got, tt.want)
+		}
+	}
+}
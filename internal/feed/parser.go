@@ -3,6 +3,7 @@ package feed
 import (
 	"crypto/sha256"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,22 +22,30 @@ func ParseRSS(feedURL string) ([]model.Article, error) {
 		return nil, fmt.Errorf("parsing feed %s: %w", feedURL, err)
 	}
 
+	return mapItems(feedURL, parsed), nil
+}
+
+// mapItems maps every item of a parsed feed. Sources that get feed
+// documents some other way than HTTP share it with ParseRSS.
+func mapItems(feedURL string, parsed *gofeed.Feed) []model.Article {
 	articles := make([]model.Article, 0, len(parsed.Items))
 	for _, item := range parsed.Items {
 		articles = append(articles, mapItem(feedURL, item))
 	}
-	return articles, nil
+	return articles
 }
 
-// mapItem converts a gofeed.Item into our Article model.
+// mapItem converts a gofeed.Item into our Article model. FeedID is left
+// for the fetcher to fill in, since it's a config concern, not a feed one.
 func mapItem(feedURL string, item *gofeed.Item) model.Article {
 	a := model.Article{
 		GUID:    itemGUID(feedURL, item),
-		FeedURL: feedURL,
 		Title:   item.Title,
 		URL:     item.Link,
 		Summary: itemSummary(item),
 		Content: item.Content,
+
+		Enclosures: itemEnclosures(item),
 	}
 
 	if item.Author != nil {
@@ -83,5 +92,63 @@ func itemSummary(item *gofeed.Item) string {
 	if item.Description != "" {
 		return strings.TrimSpace(item.Description)
 	}
+	// WHY: Podcast feeds often describe episodes only in iTunes tags.
+	if item.ITunesExt != nil {
+		if item.ITunesExt.Summary != "" {
+			return strings.TrimSpace(item.ITunesExt.Summary)
+		}
+		return strings.TrimSpace(item.ITunesExt.Subtitle)
+	}
 	return ""
 }
+
+// itemEnclosures returns the item's media files. gofeed collects RSS
+// <enclosure> elements and Atom rel="enclosure" links alike; the iTunes
+// duration is given per item, so it's copied onto each enclosure.
+func itemEnclosures(item *gofeed.Item) []model.Enclosure {
+	var duration time.Duration
+	if item.ITunesExt != nil {
+		duration = parseITunesDuration(item.ITunesExt.Duration)
+	}
+
+	var out []model.Enclosure
+	for _, e := range item.Enclosures {
+		if e == nil || strings.TrimSpace(e.URL) == "" {
+			continue
+		}
+		// A missing or garbled length is common and just means unknown.
+		length, err := strconv.ParseInt(strings.TrimSpace(e.Length), 10, 64)
+		if err != nil || length < 0 {
+			length = 0
+		}
+		out = append(out, model.Enclosure{
+			URL:      strings.TrimSpace(e.URL),
+			Type:     strings.TrimSpace(e.Type),
+			Length:   length,
+			Duration: duration,
+		})
+	}
+	return out
+}
+
+// parseITunesDuration parses an <itunes:duration>: a number of seconds
+// ("3723") or a clock time ("1:02:03", "62:03"). Anything else is zero.
+func parseITunesDuration(s string) time.Duration {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0
+	}
+	var seconds float64
+	for _, p := range parts {
+		n, err := strconv.ParseFloat(p, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
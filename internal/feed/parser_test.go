@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mayknxyz/my-feeder/internal/model"
 	"github.com/mmcdole/gofeed"
 )
 
@@ -45,9 +46,6 @@ func TestMapItem_FullItem(t *testing.T) {
 	if a.NormalizedTitle != "go 124 released" {
 		t.Errorf("NormalizedTitle = %q", a.NormalizedTitle)
 	}
-	if a.FeedURL != "https://go.dev/blog/feed.atom" {
-		t.Errorf("FeedURL = %q", a.FeedURL)
-	}
 }
 
 func TestMapItem_NoGUID_FallbackToLink(t *testing.T) {
@@ -118,3 +116,65 @@ func TestMapItem_DescriptionPreferredOverContent(t *testing.T) {
 		t.Errorf("Summary should prefer description, got %q", a.Summary)
 	}
 }
+
+func TestMapItems_PodcastEnclosures(t *testing.T) {
+	const rss = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+<channel>
+  <title>Go Time</title>
+  <item>
+    <guid>gotime-300</guid>
+    <title>Episode 300</title>
+    <itunes:summary>We look back at 300 episodes.</itunes:summary>
+    <itunes:duration>1:02:03</itunes:duration>
+    <enclosure url="https://cdn.example.com/gotime-300.mp3" type="audio/mpeg" length="59613274"/>
+  </item>
+  <item>
+    <guid>gotime-299</guid>
+    <title>Episode 299</title>
+    <enclosure url="https://cdn.example.com/gotime-299.mp3" type="audio/mpeg" length=""/>
+  </item>
+</channel>
+</rss>`
+	parsed, err := gofeed.NewParser().ParseString(rss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	articles := mapItems("https://example.com/gotime.xml", parsed)
+
+	want := model.Enclosure{
+		URL:      "https://cdn.example.com/gotime-300.mp3",
+		Type:     "audio/mpeg",
+		Length:   59613274,
+		Duration: time.Hour + 2*time.Minute + 3*time.Second,
+	}
+	if len(articles[0].Enclosures) != 1 || articles[0].Enclosures[0] != want {
+		t.Errorf("Enclosures = %+v, want [%+v]", articles[0].Enclosures, want)
+	}
+	if articles[0].Summary != "We look back at 300 episodes." {
+		t.Errorf("Summary = %q, want the iTunes summary", articles[0].Summary)
+	}
+	if e := articles[1].Enclosures; len(e) != 1 || e[0].Length != 0 || e[0].Duration != 0 {
+		t.Errorf("Enclosures = %+v, want one with unknown length and duration", e)
+	}
+}
+
+func TestParseITunesDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"3723", time.Hour + 2*time.Minute + 3*time.Second},
+		{"62:03", 62*time.Minute + 3*time.Second},
+		{"1:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"90.5", 90*time.Second + 500*time.Millisecond},
+		{"", 0},
+		{"about an hour", 0},
+		{"1:2:3:4", 0},
+	}
+	for _, tt := range tests {
+		if got := parseITunesDuration(tt.in); got != tt.want {
+			t.Errorf("parseITunesDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/deps"
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// DefaultPyPIURL is the default PyPI endpoint. The npm and crates.io
+// defaults are shared with the dependency resolver, in package deps.
+const DefaultPyPIURL = "https://pypi.org"
+
+// maxRegistryVersions caps how many versions one refresh of a package
+// reports. Registries return a package's whole history in one response,
+// and only the recent end of it is news.
+const maxRegistryVersions = 25
+
+// Registries fetches published versions from package registries.
+type Registries struct {
+	NPMURL    string
+	PyPIURL   string
+	CratesURL string
+	Client    *http.Client
+}
+
+// packageVersion is a registry-neutral published version, the registry
+// counterpart of releaseInfo.
+type packageVersion struct {
+	version    string
+	published  time.Time
+	author     string
+	prerelease bool
+	withdrawn  string // "yanked" or "deprecated", empty if neither
+	reason     string
+}
+
+// NPM fetches the versions of an npm package ("react", "@scope/pkg").
+func (r *Registries) NPM(ctx context.Context, pkg string) ([]model.Article, error) {
+	var doc struct {
+		Time     map[string]time.Time `json:"time"`
+		Versions map[string]struct {
+			Deprecated string `json:"deprecated"`
+			NPMUser    struct {
+				Name string `json:"name"`
+			} `json:"_npmUser"`
+		} `json:"versions"`
+	}
+	// LEARN: Scoped names keep their '@' but escape the '/', which is
+	// how the registry addresses "@scope/pkg" as a single path segment.
+	api := httpx.OrDefault(r.NPMURL, deps.DefaultNPMRegistry) + "/" + strings.Replace(pkg, "/", "%2F", 1)
+	if err := httpx.GetJSON(ctx, r.Client, api, nil, &doc); err != nil {
+		return nil, fmt.Errorf("fetching npm package %s: %w", pkg, err)
+	}
+
+	var versions []packageVersion
+	for v, meta := range doc.Versions {
+		pv := packageVersion{
+			version:   v,
+			published: doc.Time[v],
+			author:    meta.NPMUser.Name,
+		}
+		if meta.Deprecated != "" {
+			pv.withdrawn, pv.reason = "deprecated", meta.Deprecated
+		}
+		versions = append(versions, pv)
+	}
+	return registryArticles("npm", pkg, "https://www.npmjs.com/package/"+pkg+"/v/", versions), nil
+}
+
+// PyPI fetches the versions of a Python package.
+func (r *Registries) PyPI(ctx context.Context, pkg string) ([]model.Article, error) {
+	var doc struct {
+		Releases map[string][]struct {
+			UploadTime   time.Time `json:"upload_time_iso_8601"`
+			Yanked       bool      `json:"yanked"`
+			YankedReason string    `json:"yanked_reason"`
+		} `json:"releases"`
+	}
+	base := httpx.OrDefault(r.PyPIURL, DefaultPyPIURL)
+	api := fmt.Sprintf("%s/pypi/%s/json", base, url.PathEscape(pkg))
+	if err := httpx.GetJSON(ctx, r.Client, api, nil, &doc); err != nil {
+		return nil, fmt.Errorf("fetching PyPI package %s: %w", pkg, err)
+	}
+
+	var versions []packageVersion
+	for v, files := range doc.Releases {
+		// WHY: A release with no files was registered but never
+		// uploaded — there's nothing to install.
+		if len(files) == 0 {
+			continue
+		}
+		pv := packageVersion{version: v, prerelease: pep440Prerelease.MatchString(v)}
+		for _, f := range files {
+			if pv.published.IsZero() || f.UploadTime.Before(pv.published) {
+				pv.published = f.UploadTime
+			}
+			if f.Yanked {
+				pv.withdrawn, pv.reason = "yanked", f.YankedReason
+			}
+		}
+		versions = append(versions, pv)
+	}
+	// WHY: Mirrors and private indexes that serve the JSON API also serve
+	// project pages, so links follow the configured index.
+	return registryArticles("pypi", pkg, base+"/project/"+pkg+"/", versions), nil
+}
+
+// Crates fetches the versions of a Rust crate.
+func (r *Registries) Crates(ctx context.Context, crate string) ([]model.Article, error) {
+	var doc struct {
+		Versions []struct {
+			Num         string    `json:"num"`
+			CreatedAt   time.Time `json:"created_at"`
+			Yanked      bool      `json:"yanked"`
+			YankMessage string    `json:"yank_message"`
+			PublishedBy *struct {
+				Login string `json:"login"`
+			} `json:"published_by"`
+		} `json:"versions"`
+	}
+	api := fmt.Sprintf("%s/api/v1/crates/%s", httpx.OrDefault(r.CratesURL, deps.DefaultCratesURL), url.PathEscape(crate))
+	if err := httpx.GetJSON(ctx, r.Client, api, nil, &doc); err != nil {
+		return nil, fmt.Errorf("fetching crate %s: %w", crate, err)
+	}
+
+	var versions []packageVersion
+	for _, v := range doc.Versions {
+		pv := packageVersion{version: v.Num, published: v.CreatedAt}
+		if v.PublishedBy != nil {
+			pv.author = v.PublishedBy.Login
+		}
+		if v.Yanked {
+			pv.withdrawn, pv.reason = "yanked", v.YankMessage
+		}
+		versions = append(versions, pv)
+	}
+	return registryArticles("crates", crate, "https://crates.io/crates/"+crate+"/", versions), nil
+}
+
+// pep440Prerelease matches PEP 440 alpha, beta, release-candidate and
+// dev versions ("2.0a1", "2.0.0rc2", "1.4.dev3").
+var pep440Prerelease = regexp.MustCompile(`(?i)\d[._-]?(a|alpha|b|beta|c|rc|pre|preview|dev)\d*`)
+
+// pep440PostRelease matches a PEP 440 post-release suffix (".post1").
+var pep440PostRelease = regexp.MustCompile(`(?i)[._-]?post(\d*)$`)
+
+// registryArticles maps the newest versions of a package to Articles.
+// versionURL is the package page prefix each version is appended to.
+func registryArticles(source, pkg, versionURL string, versions []packageVersion) []model.Article {
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].published.After(versions[j].published)
+	})
+	versions = versions[:min(len(versions), maxRegistryVersions)]
+
+	articles := make([]model.Article, 0, len(versions))
+	for _, pv := range versions {
+		a := releaseInfo{
+			guid:        fmt.Sprintf("%s:%s@%s", source, pkg, pv.version),
+			repo:        pkg,
+			tag:         pv.version,
+			url:         versionURL + pv.version,
+			author:      pv.author,
+			prerelease:  pv.prerelease,
+			publishedAt: pv.published,
+		}.article()
+
+		if source == "pypi" {
+			// WHY: A post-release ("1.0.post1") follows its base release,
+			// but the semver-style parser would read the suffix as a
+			// prerelease. As build metadata it's ignored instead.
+			a.Version = pep440PostRelease.ReplaceAllString(pv.version, "+post$1")
+		}
+
+		if pv.withdrawn != "" {
+			// WHY: Versions are usually yanked after they were first
+			// fetched. With the status in the GUID and URL, the yank
+			// arrives as an article of its own rather than being deduped
+			// against the release it withdraws.
+			a.GUID += "#" + pv.withdrawn
+			a.URL += "#" + pv.withdrawn
+			a.Title += " (" + pv.withdrawn + ")"
+			a.Summary = strings.ToUpper(pv.withdrawn[:1]) + pv.withdrawn[1:]
+			if pv.reason != "" {
+				a.Summary += ": " + pv.reason
+			}
+			a.Content = a.Summary
+			// WHY: The title differs from the release's by the status
+			// alone, so fuzzy matching would drop the withdrawal as a
+			// duplicate of the release. An empty NormalizedTitle keeps the
+			// article out of title matching.
+			a.NormalizedTitle = ""
+		}
+		articles = append(articles, a)
+	}
+	return articles
+}
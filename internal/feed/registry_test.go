@@ -0,0 +1,160 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/release"
+)
+
+// newTestRegistries serves recorded registry responses from testdata,
+// keyed by request path.
+func newTestRegistries(t *testing.T, fixtures map[string]string) *Registries {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, ok := fixtures[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "testdata/"+file)
+	}))
+	t.Cleanup(srv.Close)
+	return &Registries{NPMURL: srv.URL, PyPIURL: srv.URL, CratesURL: srv.URL, Client: srv.Client()}
+}
+
+// byVersion indexes articles by their Version for easy assertions.
+func byVersion(articles []model.Article) map[string]model.Article {
+	m := make(map[string]model.Article, len(articles))
+	for _, a := range articles {
+		m[a.Version] = a
+	}
+	return m
+}
+
+func TestRegistriesNPM(t *testing.T) {
+	r := newTestRegistries(t, map[string]string{"/left-pad": "npm-left-pad.json"})
+
+	articles, err := r.NPM(t.Context(), "left-pad")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 4 {
+		t.Fatalf("got %d articles, want 4", len(articles))
+	}
+	if articles[0].Version != "2.0.0-beta.1" {
+		t.Errorf("newest = %q, want 2.0.0-beta.1", articles[0].Version)
+	}
+
+	deprecated := byVersion(articles)["1.3.0"]
+	if deprecated.Title != "left-pad 1.3.0 (deprecated)" {
+		t.Errorf("Title = %q", deprecated.Title)
+	}
+	if deprecated.Summary != "Deprecated: use String.prototype.padStart()" {
+		t.Errorf("Summary = %q", deprecated.Summary)
+	}
+	if deprecated.GUID != "npm:left-pad@1.3.0#deprecated" || deprecated.URL != "https://www.npmjs.com/package/left-pad/v/1.3.0#deprecated" {
+		t.Errorf("GUID, URL = %q, %q", deprecated.GUID, deprecated.URL)
+	}
+	if deprecated.Author != "stevemao" {
+		t.Errorf("Author = %q", deprecated.Author)
+	}
+}
+
+func TestRegistriesNPM_ScopedPackage(t *testing.T) {
+	r := newTestRegistries(t, map[string]string{"/@types%2Fnode": "npm-left-pad.json"})
+	if _, err := r.NPM(t.Context(), "@types/node"); err != nil {
+		t.Errorf("scoped package request: %v", err)
+	}
+}
+
+func TestRegistriesPyPI(t *testing.T) {
+	r := newTestRegistries(t, map[string]string{"/pypi/requests/json": "pypi-requests.json"})
+
+	articles, err := r.PyPI(t.Context(), "requests")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	versions := byVersion(articles)
+	if len(articles) != 4 {
+		t.Fatalf("got %d articles, want 4 (3.0.0 has no files)", len(articles))
+	}
+
+	yanked := versions["2.32.0"]
+	if yanked.Title != "requests 2.32.0 (yanked)" {
+		t.Errorf("Title = %q", yanked.Title)
+	}
+	if want := r.PyPIURL + "/project/requests/2.32.0#yanked"; yanked.URL != want {
+		t.Errorf("URL = %q, want %q on the configured index", yanked.URL, want)
+	}
+	if got := yanked.PublishedAt.Format("2006-01-02T15:04:05"); got != "2024-05-20T15:02:34" {
+		t.Errorf("PublishedAt = %s, want earliest upload", got)
+	}
+	if !versions["3.0.0rc1"].Prerelease {
+		t.Error("3.0.0rc1 should be a prerelease")
+	}
+
+	post, ok := versions["2.32.3+post1"]
+	if !ok {
+		t.Fatalf("post-release version not normalised: %v", versions)
+	}
+	if post.Prerelease || post.Title != "requests 2.32.3.post1" {
+		t.Errorf("post-release = %q (prerelease %v)", post.Title, post.Prerelease)
+	}
+
+	// The post-release must survive a stable-only filter.
+	f, err := release.NewFilter(model.Feed{Prereleases: new(bool)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := byVersion(f.Apply(articles))
+	if _, ok := kept["2.32.3+post1"]; !ok {
+		t.Error("prereleases = false dropped a post-release")
+	}
+	if _, ok := kept["3.0.0rc1"]; ok {
+		t.Error("prereleases = false kept 3.0.0rc1")
+	}
+}
+
+func TestRegistriesCrates(t *testing.T) {
+	r := newTestRegistries(t, map[string]string{"/api/v1/crates/serde": "crates-serde.json"})
+
+	articles, err := r.Crates(t.Context(), "serde")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("got %d articles, want 3", len(articles))
+	}
+	if articles[0].Version != "1.0.210" || articles[0].Author != "dtolnay" {
+		t.Errorf("newest = %q by %q", articles[0].Version, articles[0].Author)
+	}
+	yanked := byVersion(articles)["1.0.209"]
+	if yanked.Summary != "Yanked: broken on 1.56" {
+		t.Errorf("Summary = %q", yanked.Summary)
+	}
+	if yanked.URL != "https://crates.io/crates/serde/1.0.209#yanked" {
+		t.Errorf("URL = %q", yanked.URL)
+	}
+
+	// A refresh before the yank cached the release; the yank is still
+	// news.
+	release := releaseInfo{
+		guid: "crates:serde@1.0.209",
+		repo: "serde",
+		tag:  "1.0.209",
+		url:  "https://crates.io/crates/serde/1.0.209",
+	}.article()
+	if IsDuplicate(yanked, []model.Article{release}, 30) {
+		t.Error("yank of a cached version was deduped away")
+	}
+}
+
+func TestRegistries_NotFound(t *testing.T) {
+	r := newTestRegistries(t, nil)
+	if _, err := r.Crates(t.Context(), "missing"); err == nil {
+		t.Error("expected error for unknown crate")
+	}
+}
@@ -0,0 +1,321 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errUnsupportedRule marks an RRULE using parts this expander doesn't
+// implement. Callers fall back to the event's first occurrence.
+var errUnsupportedRule = errors.New("unsupported recurrence rule")
+
+// maxRulePeriods bounds how many days, weeks, months or years a rule is
+// walked through, so a malformed rule can't spin forever.
+const maxRulePeriods = 100_000
+
+// rrule is a parsed RFC 5545 recurrence rule, limited to what calendars
+// for meetups and conferences use: DAILY to YEARLY with INTERVAL, COUNT,
+// UNTIL, BYDAY, BYMONTHDAY and BYMONTH.
+type rrule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []time.Month
+}
+
+// weekdayNum is a BYDAY entry: "TU" (n = 0, every Tuesday) or "-1FR"
+// (the last Friday of the month).
+type weekdayNum struct {
+	n   int
+	day time.Weekday
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRRule parses an RRULE value. UNTIL is read in loc when it's a
+// floating time, like the DTSTART it bounds.
+func parseRRule(s string, loc *time.Location) (rrule, error) {
+	r := rrule{interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return r, fmt.Errorf("rrule part %q: missing =", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("rrule interval %q", val)
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("rrule count %q", val)
+			}
+			r.count = n
+		case "UNTIL":
+			t, _, err := parseICSTime(val, nil, loc)
+			if err != nil {
+				return r, fmt.Errorf("rrule until: %w", err)
+			}
+			r.until = t
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, err := parseWeekdayNum(d)
+				if err != nil {
+					return r, err
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return r, fmt.Errorf("rrule bymonthday %q", d)
+				}
+				r.byMonthDay = append(r.byMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, m := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(m)
+				if err != nil || n < 1 || n > 12 {
+					return r, fmt.Errorf("rrule bymonth %q", m)
+				}
+				r.byMonth = append(r.byMonth, time.Month(n))
+			}
+		case "WKST":
+			// Only affects WEEKLY rules with INTERVAL > 1 and BYDAY
+			// spanning the week boundary; Monday is assumed.
+		default:
+			return r, fmt.Errorf("%w: %s", errUnsupportedRule, key)
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY":
+	case "YEARLY":
+		// WHY: Without BYMONTH, ordinal BYDAY counts weeks of the whole
+		// year ("20MO"), which nobody uses for events.
+		if len(r.byDay) > 0 && len(r.byMonth) == 0 {
+			return r, fmt.Errorf("%w: yearly BYDAY without BYMONTH", errUnsupportedRule)
+		}
+	case "":
+		return r, fmt.Errorf("rrule %q: missing FREQ", s)
+	default:
+		return r, fmt.Errorf("%w: FREQ=%s", errUnsupportedRule, r.freq)
+	}
+	return r, nil
+}
+
+// parseWeekdayNum parses a BYDAY entry like "MO", "2TU" or "-1FR".
+func parseWeekdayNum(s string) (weekdayNum, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return weekdayNum{}, fmt.Errorf("rrule byday %q", s)
+	}
+	day, ok := icsWeekdays[s[len(s)-2:]]
+	if !ok {
+		return weekdayNum{}, fmt.Errorf("rrule byday %q", s)
+	}
+	wd := weekdayNum{day: day}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return weekdayNum{}, fmt.Errorf("rrule byday %q", s)
+		}
+		wd.n = n
+	}
+	return wd, nil
+}
+
+// occurrences returns the start times of the series beginning at start,
+// up to and including to. COUNT counts from start, so it's honoured even
+// when the caller only wants a later window.
+func (r rrule) occurrences(start, to time.Time) []time.Time {
+	if start.After(to) {
+		return nil
+	}
+	// WHY: RFC 5545 makes DTSTART the first instance and counts it
+	// towards COUNT even when it doesn't match the rule, like a meetup
+	// kicked off on a Monday that then runs every Tuesday.
+	out := []time.Time{start}
+	n := 1
+	if r.count == 1 {
+		return out
+	}
+	for period := 0; period < maxRulePeriods; period++ {
+		for _, day := range r.periodDays(start, period) {
+			t := time.Date(day.Year(), day.Month(), day.Day(),
+				start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+			if !t.After(start) {
+				continue
+			}
+			if t.After(to) || (!r.until.IsZero() && t.After(r.until)) {
+				return out
+			}
+			out = append(out, t)
+			n++
+			if r.count > 0 && n == r.count {
+				return out
+			}
+		}
+	}
+	return out
+}
+
+// periodDays returns the candidate days, in order, of the period'th
+// day/week/month/year of the series. Only the date part is meaningful.
+func (r rrule) periodDays(start time.Time, period int) []time.Time {
+	y, m, d := start.Date()
+	loc := start.Location()
+	step := period * r.interval
+
+	switch r.freq {
+	case "DAILY":
+		day := time.Date(y, m, d+step, 0, 0, 0, 0, loc)
+		if r.matchesMonth(day) && r.matchesMonthDay(day) && r.matchesWeekday(day) {
+			return []time.Time{day}
+		}
+		return nil
+
+	case "WEEKLY":
+		// Weeks start on Monday (WKST=MO).
+		offset := (int(start.Weekday()) + 6) % 7
+		monday := time.Date(y, m, d-offset+7*step, 0, 0, 0, 0, loc)
+		days := []time.Weekday{start.Weekday()}
+		if len(r.byDay) > 0 {
+			days = days[:0]
+			for _, wd := range r.byDay {
+				days = append(days, wd.day)
+			}
+		}
+		var out []time.Time
+		for _, wd := range days {
+			day := monday.AddDate(0, 0, (int(wd)+6)%7)
+			if r.matchesMonth(day) {
+				out = append(out, day)
+			}
+		}
+		return sortDays(out)
+
+	case "MONTHLY":
+		first := time.Date(y, m+time.Month(step), 1, 0, 0, 0, 0, loc)
+		if !r.matchesMonth(first) {
+			return nil
+		}
+		return r.monthDays(first, d)
+
+	case "YEARLY":
+		months := r.byMonth
+		if len(months) == 0 {
+			months = []time.Month{m}
+		}
+		var out []time.Time
+		for _, month := range months {
+			out = append(out, r.monthDays(time.Date(y+step, month, 1, 0, 0, 0, 0, loc), d)...)
+		}
+		return sortDays(out)
+	}
+	return nil
+}
+
+// monthDays returns the days of the month starting at first that the
+// rule selects. Without BYMONTHDAY or BYDAY that's the start's day of
+// the month, skipped in months too short to have it.
+func (r rrule) monthDays(first time.Time, startDay int) []time.Time {
+	last := daysIn(first)
+	var out []time.Time
+	add := func(day int) {
+		if day >= 1 && day <= last {
+			out = append(out, first.AddDate(0, 0, day-1))
+		}
+	}
+
+	switch {
+	case len(r.byMonthDay) > 0:
+		for _, md := range r.byMonthDay {
+			if md < 0 {
+				md = last + md + 1
+			}
+			if md >= 1 && md <= last && r.matchesWeekday(first.AddDate(0, 0, md-1)) {
+				add(md)
+			}
+		}
+	case len(r.byDay) > 0:
+		for _, wd := range r.byDay {
+			firstOfKind := 1 + (int(wd.day)-int(first.Weekday())+7)%7
+			switch {
+			case wd.n == 0:
+				for day := firstOfKind; day <= last; day += 7 {
+					add(day)
+				}
+			case wd.n > 0:
+				add(firstOfKind + 7*(wd.n-1))
+			default:
+				lastOfKind := firstOfKind + 7*((last-firstOfKind)/7)
+				add(lastOfKind + 7*(wd.n+1))
+			}
+		}
+	default:
+		add(startDay)
+	}
+	return sortDays(out)
+}
+
+func (r rrule) matchesMonth(day time.Time) bool {
+	return len(r.byMonth) == 0 || slices.Contains(r.byMonth, day.Month())
+}
+
+func (r rrule) matchesMonthDay(day time.Time) bool {
+	if len(r.byMonthDay) == 0 {
+		return true
+	}
+	last := daysIn(day)
+	for _, md := range r.byMonthDay {
+		if md == day.Day() || last+md+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday reports whether day falls on one of BYDAY's weekdays,
+// ignoring ordinals. Used where BYDAY narrows another rule part.
+func (r rrule) matchesWeekday(day time.Time) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+	for _, wd := range r.byDay {
+		if wd.day == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// daysIn returns the number of days in t's month.
+func daysIn(t time.Time) int {
+	// LEARN: Day 0 of the next month normalises to the last day of
+	// this one.
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+// sortDays sorts days and drops duplicates, which overlapping BYDAY or
+// BYMONTHDAY entries can produce.
+func sortDays(days []time.Time) []time.Time {
+	slices.SortFunc(days, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(days, func(a, b time.Time) bool { return a.Equal(b) })
+}
@@ -0,0 +1,136 @@
+package feed
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRRuleOccurrences(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 18, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		rule  string
+		start time.Time
+		to    time.Time
+		want  []time.Time
+	}{
+		{
+			"FREQ=DAILY;INTERVAL=2;COUNT=3",
+			day(2024, 1, 30), day(2024, 12, 31),
+			[]time.Time{day(2024, 1, 30), day(2024, 2, 1), day(2024, 2, 3)},
+		},
+		{
+			"FREQ=WEEKLY;BYDAY=MO,TH",
+			day(2024, 3, 7), day(2024, 3, 15), // a Thursday
+			[]time.Time{day(2024, 3, 7), day(2024, 3, 11), day(2024, 3, 14)},
+		},
+		{
+			// A start off the rule is still the first of COUNT.
+			"FREQ=WEEKLY;BYDAY=TU;COUNT=3",
+			day(2024, 3, 4), day(2024, 12, 31), // a Monday
+			[]time.Time{day(2024, 3, 4), day(2024, 3, 5), day(2024, 3, 12)},
+		},
+		{
+			"FREQ=WEEKLY;INTERVAL=2;UNTIL=20240402T000000Z",
+			day(2024, 3, 5), day(2024, 12, 31),
+			[]time.Time{day(2024, 3, 5), day(2024, 3, 19)},
+		},
+		{
+			// Months without a 31st are skipped.
+			"FREQ=MONTHLY;COUNT=3",
+			day(2024, 1, 31), day(2024, 12, 31),
+			[]time.Time{day(2024, 1, 31), day(2024, 3, 31), day(2024, 5, 31)},
+		},
+		{
+			"FREQ=MONTHLY;BYDAY=2TU",
+			day(2024, 1, 9), day(2024, 3, 31),
+			[]time.Time{day(2024, 1, 9), day(2024, 2, 13), day(2024, 3, 12)},
+		},
+		{
+			"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			day(2024, 1, 26), day(2024, 12, 31),
+			[]time.Time{day(2024, 1, 26), day(2024, 2, 23), day(2024, 3, 29)},
+		},
+		{
+			"FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=4",
+			day(2024, 2, 1), day(2024, 12, 31),
+			[]time.Time{day(2024, 2, 1), day(2024, 2, 29), day(2024, 3, 1), day(2024, 3, 31)},
+		},
+		{
+			// Friday the 13th.
+			"FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13;COUNT=2",
+			day(2024, 9, 13), day(2026, 12, 31),
+			[]time.Time{day(2024, 9, 13), day(2024, 12, 13)},
+		},
+		{
+			// US Thanksgiving.
+			"FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;COUNT=2",
+			day(2024, 11, 28), day(2030, 1, 1),
+			[]time.Time{day(2024, 11, 28), day(2025, 11, 27)},
+		},
+		{
+			"FREQ=YEARLY",
+			day(2024, 2, 29), day(2029, 1, 1),
+			[]time.Time{day(2024, 2, 29), day(2028, 2, 29)},
+		},
+	}
+	for _, tt := range tests {
+		r, err := parseRRule(tt.rule, time.UTC)
+		if err != nil {
+			t.Errorf("parseRRule(%q) error: %v", tt.rule, err)
+			continue
+		}
+		got := r.occurrences(tt.start, tt.to)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.rule, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(tt.want[i]) {
+				t.Errorf("%s: [%d] = %v, want %v", tt.rule, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestRRuleOccurrences_KeepsWallClockAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no zone data: %v", err)
+	}
+	r, err := parseRRule("FREQ=WEEKLY;COUNT=2", berlin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clocks go forward on 31 March 2024.
+	got := r.occurrences(time.Date(2024, 3, 26, 18, 30, 0, 0, berlin), time.Date(2025, 1, 1, 0, 0, 0, 0, berlin))
+	if len(got) != 2 || got[1].Hour() != 18 || got[1].Minute() != 30 {
+		t.Errorf("occurrences = %v, want 18:30 both weeks", got)
+	}
+}
+
+func TestParseRRule_Errors(t *testing.T) {
+	tests := []struct {
+		rule        string
+		unsupported bool
+	}{
+		{"FREQ=HOURLY", true},
+		{"FREQ=MONTHLY;BYSETPOS=-1;BYDAY=MO,TU,WE,TH,FR", true},
+		{"FREQ=YEARLY;BYDAY=20MO", true},
+		{"INTERVAL=2", false},
+		{"FREQ=WEEKLY;BYDAY=XX", false},
+		{"FREQ=DAILY;COUNT=0", false},
+	}
+	for _, tt := range tests {
+		_, err := parseRRule(tt.rule, time.UTC)
+		if err == nil {
+			t.Errorf("parseRRule(%q) succeeded, want error", tt.rule)
+			continue
+		}
+		if got := errors.Is(err, errUnsupportedRule); got != tt.unsupported {
+			t.Errorf("parseRRule(%q) unsupported = %v, want %v (%v)", tt.rule, got, tt.unsupported, err)
+		}
+	}
+}
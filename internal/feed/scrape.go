@@ -0,0 +1,197 @@
+package feed
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/charmbracelet/log"
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// userDateLayouts are tried, after the ISO 8601 shapes, when a feed
+// that extracts dates itself has no date_format.
+var userDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+// Scraper builds articles from HTML index pages that have no feed,
+// using the CSS selectors in a feed's [feeds.scrape] block.
+type Scraper struct {
+	Client *http.Client
+}
+
+// Scrape fetches a scrape: feed's page and extracts one article per
+// element matching its item selector.
+func (s *Scraper) Scrape(ctx context.Context, feed model.Feed) ([]model.Article, error) {
+	pageURL := feed.Target()
+	if feed.Scrape == nil {
+		return nil, fmt.Errorf("scrape feed %q has no [feeds.scrape] block", feed.Name)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request for %s: %w", pageURL, err)
+	}
+	req.Header.Set("User-Agent", httpx.UserAgent)
+
+	resp, err := httpx.Client(s.Client).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", pageURL, resp.Status)
+	}
+
+	// WHY: Relative links resolve against where the page ended up, not
+	// where we asked for it, in case the request was redirected.
+	articles, err := scrapeArticles(resp.Body, resp.Request.URL, *feed.Scrape)
+	if err != nil {
+		return nil, fmt.Errorf("scraping %s: %w", pageURL, err)
+	}
+	return articles, nil
+}
+
+// scrapeArticles extracts articles from an HTML page. Items without a
+// title are skipped; a page with no items at all is an error, since it
+// usually means the site's markup changed under the selectors.
+func scrapeArticles(r io.Reader, base *url.URL, opts model.ScrapeOptions) ([]model.Article, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := base.Parse(href); err == nil {
+			base = u
+		}
+	}
+
+	items := doc.Find(opts.Item)
+	if items.Length() == 0 {
+		return nil, fmt.Errorf("item selector %q matched nothing", opts.Item)
+	}
+
+	var articles []model.Article
+	undated := 0
+	items.Each(func(_ int, item *goquery.Selection) {
+		titleSel := item.Find(opts.Title).First()
+		title := selText(titleSel)
+		if title == "" {
+			return
+		}
+
+		a := model.Article{
+			Title:     title,
+			URL:       resolveLink(base, scrapeLink(item, titleSel, opts.Link)),
+			FetchedAt: time.Now(),
+		}
+		if opts.Summary != "" {
+			a.Summary = selText(item.Find(opts.Summary).First())
+		}
+
+		a.PublishedAt = time.Now()
+		if opts.Date != "" {
+			dateSel := item.Find(opts.Date).First()
+			// LEARN: <time datetime="..."> carries a machine-readable
+			// date even when the visible text is "3 days ago".
+			raw, ok := dateSel.Attr("datetime")
+			if !ok {
+				raw = selText(dateSel)
+			}
+			if t, err := parseUserDate(raw, opts.DateFormat); err == nil {
+				a.PublishedAt = t
+			} else {
+				undated++
+			}
+		}
+
+		a.GUID = a.URL
+		if a.GUID == "" {
+			h := sha256.Sum256([]byte(base.String() + "|" + title))
+			a.GUID = fmt.Sprintf("sha256:%x", h[:8])
+		}
+		a.NormalizedTitle = NormalizeTitle(a.Title)
+		articles = append(articles, a)
+	})
+
+	if undated > 0 {
+		log.Warn("Scraped dates didn't parse, using fetch time",
+			"page", base.String(), "items", undated, "date_format", opts.DateFormat)
+	}
+	return articles, nil
+}
+
+// scrapeLink returns the raw href for an item: from the link selector if
+// set, else from the title element or the item's first link.
+func scrapeLink(item, title *goquery.Selection, selector string) string {
+	if selector != "" {
+		return linkHref(item.Find(selector).First())
+	}
+	if href := linkHref(title); href != "" {
+		return href
+	}
+	if href, ok := title.Closest("a[href]").Attr("href"); ok {
+		return href
+	}
+	return linkHref(item)
+}
+
+// linkHref returns the href of sel itself, or of its first descendant
+// link.
+func linkHref(sel *goquery.Selection) string {
+	if href, ok := sel.Attr("href"); ok {
+		return href
+	}
+	href, _ := sel.Find("a[href]").First().Attr("href")
+	return href
+}
+
+// resolveLink makes href absolute against the page URL. Empty or
+// unparseable hrefs resolve to "".
+func resolveLink(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+	u, err := base.Parse(href)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// selText returns an element's text with whitespace collapsed.
+func selText(sel *goquery.Selection) string {
+	return strings.Join(strings.Fields(sel.Text()), " ")
+}
+
+// parseUserDate parses a date pulled out of a page or API response with
+// the feed's date_format, or with the built-in layouts when it has none.
+func parseUserDate(s, layout string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if layout != "" {
+		return time.ParseInLocation(layout, s, time.Local)
+	}
+	if t, err := parseDocDate(s); err == nil {
+		return t, nil
+	}
+	for _, l := range userDateLayouts {
+		if t, err := time.ParseInLocation(l, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", s)
+}
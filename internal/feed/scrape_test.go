@@ -0,0 +1,132 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+func TestScrapeArticles(t *testing.T) {
+	f, err := os.Open("testdata/scrape-blog.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	base, _ := url.Parse("https://example.com/blog/index.html")
+	articles, err := scrapeArticles(f, base, model.ScrapeOptions{
+		Item:    "article.post",
+		Title:   ".post-title",
+		Date:    "time, .date",
+		Summary: ".excerpt",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The untitled draft is skipped.
+	if len(articles) != 3 {
+		t.Fatalf("got %d articles, want 3", len(articles))
+	}
+
+	tests := []struct {
+		title, url string
+		date       time.Time
+	}{
+		{"Tuning the garbage collector", "https://example.com/blog/posts/tuning-gc", time.Date(2024, 3, 14, 9, 30, 0, 0, time.UTC)},
+		{"Release notes for 2.0", "https://cdn.example.org/notes/2.0", time.Date(2024, 3, 2, 0, 0, 0, 0, time.Local)},
+		{"Slides from GopherCon", "https://example.com/talks/gophercon", time.Time{}},
+	}
+	for i, tt := range tests {
+		a := articles[i]
+		if a.Title != tt.title {
+			t.Errorf("articles[%d].Title = %q, want %q", i, a.Title, tt.title)
+		}
+		if a.URL != tt.url {
+			t.Errorf("articles[%d].URL = %q, want %q", i, a.URL, tt.url)
+		}
+		if a.GUID != tt.url {
+			t.Errorf("articles[%d].GUID = %q, want %q", i, a.GUID, tt.url)
+		}
+		if !tt.date.IsZero() && !a.PublishedAt.Equal(tt.date) {
+			t.Errorf("articles[%d].PublishedAt = %v, want %v", i, a.PublishedAt, tt.date)
+		}
+		if a.NormalizedTitle == "" {
+			t.Errorf("articles[%d] has no NormalizedTitle; scraped items should be fuzzy-deduped", i)
+		}
+	}
+
+	if got := articles[0].Summary; got != "What GOGC and GOMEMLIMIT actually do, and when to touch them." {
+		t.Errorf("Summary = %q", got)
+	}
+	// An unparseable date falls back to the fetch time.
+	if time.Since(articles[2].PublishedAt) > time.Minute {
+		t.Errorf("undated article PublishedAt = %v, want about now", articles[2].PublishedAt)
+	}
+}
+
+func TestScrapeArticles_NoItems(t *testing.T) {
+	base, _ := url.Parse("https://example.com/")
+	_, err := scrapeArticles(strings.NewReader("<p>redesigned</p>"), base, model.ScrapeOptions{Item: "article", Title: "h2"})
+	if err == nil || !strings.Contains(err.Error(), "matched nothing") {
+		t.Errorf("err = %v, want item selector error", err)
+	}
+}
+
+func TestParseUserDate(t *testing.T) {
+	tests := []struct {
+		in, layout string
+		want       time.Time
+		wantErr    bool
+	}{
+		{"2024-03-14", "", time.Date(2024, 3, 14, 0, 0, 0, 0, time.Local), false},
+		{"Mar 2, 2024", "", time.Date(2024, 3, 2, 0, 0, 0, 0, time.Local), false},
+		{"14.03.2024", "02.01.2006", time.Date(2024, 3, 14, 0, 0, 0, 0, time.Local), false},
+		{"14.03.2024", "", time.Time{}, true},
+		{" 2 January 2024 ", "", time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local), false},
+	}
+	for _, tt := range tests {
+		got, err := parseUserDate(tt.in, tt.layout)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseUserDate(%q, %q) err = %v, wantErr %v", tt.in, tt.layout, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseUserDate(%q, %q) = %v, want %v", tt.in, tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestScraperScrape(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/news/", http.StatusMovedPermanently)
+			return
+		}
+		w.Write([]byte(`<ul><li><a href="first">First post</a></li><li><a href="second">Second post</a></li></ul>`))
+	}))
+	defer srv.Close()
+
+	s := &Scraper{Client: srv.Client()}
+	articles, err := s.Scrape(t.Context(), model.Feed{
+		Name:   "News",
+		URL:    "scrape:" + srv.URL + "/old",
+		Scrape: &model.ScrapeOptions{Item: "li", Title: "a"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("got %d articles, want 2", len(articles))
+	}
+	// Links resolve against the redirected URL.
+	if want := srv.URL + "/news/first"; articles[0].URL != want {
+		t.Errorf("URL = %q, want %q", articles[0].URL, want)
+	}
+}
@@ -0,0 +1,339 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+// diffContext is how many unchanged lines are shown around each change.
+const diffContext = 2
+
+// Watcher turns changes to a web page into articles. It keeps the last
+// seen text of each watched page in SnapshotDir, one file per feed.
+type Watcher struct {
+	SnapshotDir string
+	Client      *http.Client
+}
+
+// Check fetches a watch: feed's page and, if its text changed since the
+// last snapshot, returns one article holding the diff. The first check
+// only records a snapshot.
+//
+// A new snapshot is staged beside the current one, and the returned
+// cursor's LatestID holds its hash. It replaces the current snapshot on
+// the next check, once a cursor carrying that hash shows the cache
+// holding the article was saved.
+func (w *Watcher) Check(ctx context.Context, feed model.Feed, cursor store.Cursor) ([]model.Article, store.Cursor, error) {
+	pageURL := feed.Target()
+	var selector string
+	if feed.Watch != nil {
+		selector = feed.Watch.Selector
+	}
+
+	path := filepath.Join(w.SnapshotDir, feed.ID+".txt")
+	if err := promoteSnapshot(path, cursor.LatestID); err != nil {
+		return nil, cursor, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, cursor, fmt.Errorf("building request for %s: %w", pageURL, err)
+	}
+	req.Header.Set("User-Agent", httpx.UserAgent)
+	if cursor.ETag != "" {
+		req.Header.Set("If-None-Match", cursor.ETag)
+	}
+
+	resp, err := httpx.Client(w.Client).Do(req)
+	if err != nil {
+		return nil, cursor, fmt.Errorf("fetching %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, cursor, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, cursor, fmt.Errorf("fetching %s: %s", pageURL, resp.Status)
+	}
+
+	text, err := pageText(resp.Body, selector)
+	if err != nil {
+		return nil, cursor, fmt.Errorf("reading %s: %w", pageURL, err)
+	}
+	next := cursor
+	next.ETag = resp.Header.Get("ETag")
+
+	prev, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, cursor, fmt.Errorf("reading snapshot: %w", err)
+	}
+	if err == nil && string(prev) == text {
+		return nil, next, nil
+	}
+	// WHY: If the cache isn't saved after this check, the article is
+	// lost. Staging the snapshot leaves the current one in place, so
+	// the next check reports the change again.
+	hash := sha256.Sum256([]byte(text))
+	if err := writeSnapshot(path+".pending", text); err != nil {
+		return nil, cursor, err
+	}
+	next.LatestID = fmt.Sprintf("%x", hash[:8])
+	if prev == nil {
+		return nil, next, nil
+	}
+
+	lines := diffLines(splitLines(string(prev)), splitLines(text))
+	added, removed := 0, 0
+	for _, l := range lines {
+		switch l.op {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+
+	// WHY: The check time is part of the GUID because a page can return
+	// to content it had before — a price reverted, a banner put back —
+	// and that is a change too.
+	now := time.Now()
+	a := model.Article{
+		GUID:        fmt.Sprintf("watch:%s#%x@%s", pageURL, hash[:8], now.UTC().Format(time.RFC3339Nano)),
+		Title:       fmt.Sprintf("%s changed (+%d −%d lines)", feed.Name, added, removed),
+		URL:         pageURL,
+		Summary:     diffSummary(lines),
+		Content:     "```diff\n" + formatDiff(lines, diffContext) + "```\n",
+		PublishedAt: now,
+		FetchedAt:   now,
+	}
+	return []model.Article{a}, next, nil
+}
+
+// pageText extracts the normalised text of a page, or of the elements
+// matching selector. Whitespace within lines is collapsed and blank
+// lines dropped, so reflowed markup doesn't count as a change.
+func pageText(r io.Reader, selector string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return "", err
+	}
+	sel := doc.Find("body")
+	if selector != "" {
+		sel = doc.Find(selector)
+		if sel.Length() == 0 {
+			return "", fmt.Errorf("selector %q matched nothing", selector)
+		}
+	}
+
+	var b strings.Builder
+	sel.Each(func(_ int, s *goquery.Selection) {
+		// LEARN: goquery.OuterHtml renders the element itself plus its
+		// children, so htmlText sees the block structure it needs.
+		h, err := goquery.OuterHtml(s)
+		if err != nil {
+			return
+		}
+		for _, line := range strings.Split(htmlText(h), "\n") {
+			if line = strings.Join(strings.Fields(line), " "); line != "" {
+				b.WriteString(line)
+				b.WriteByte('\n')
+			}
+		}
+	})
+	return b.String(), nil
+}
+
+// promoteSnapshot replaces the snapshot at path with the one staged by
+// the previous check if its hash matches the saved cursor's, and
+// discards the staged one otherwise.
+func promoteSnapshot(path, savedHash string) error {
+	pending := path + ".pending"
+	staged, err := os.ReadFile(pending)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading staged snapshot: %w", err)
+	}
+	hash := sha256.Sum256(staged)
+	if fmt.Sprintf("%x", hash[:8]) != savedHash {
+		if err := os.Remove(pending); err != nil {
+			return fmt.Errorf("discarding staged snapshot: %w", err)
+		}
+		return nil
+	}
+	if err := os.Rename(pending, path); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// writeSnapshot replaces a snapshot atomically, so a crash mid-write
+// can't leave a truncated file that diffs as "everything removed".
+func writeSnapshot(path, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(text), 0o644); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// splitLines splits text into lines without a trailing empty one.
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diffLine is one line of a diff: ' ' unchanged, '-' removed, '+' added.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// maxDiffCells bounds the LCS table. Past it, the changed middle of the
+// page is reported as wholly replaced rather than diffed line by line.
+const maxDiffCells = 4_000_000
+
+// diffLines computes a line diff of a and b.
+func diffLines(a, b []string) []diffLine {
+	// WHY: Page edits are usually local. Stripping the common prefix and
+	// suffix first keeps the quadratic LCS table small.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var out []diffLine
+	for _, l := range a[:pre] {
+		out = append(out, diffLine{' ', l})
+	}
+	out = append(out, lcsDiff(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, l := range a[len(a)-suf:] {
+		out = append(out, diffLine{' ', l})
+	}
+	return out
+}
+
+// lcsDiff diffs two line slices via a longest-common-subsequence table.
+func lcsDiff(a, b []string) []diffLine {
+	var out []diffLine
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, l := range a {
+			out = append(out, diffLine{'-', l})
+		}
+		for _, l := range b {
+			out = append(out, diffLine{'+', l})
+		}
+		return out
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, diffLine{' ', a[i]})
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, diffLine{'-', a[i]})
+			i++
+		default:
+			out = append(out, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		out = append(out, diffLine{'+', b[j]})
+	}
+	return out
+}
+
+// formatDiff renders changed lines with up to context unchanged lines
+// around them, separating distant hunks with "...".
+func formatDiff(lines []diffLine, context int) string {
+	show := make([]bool, len(lines))
+	for i, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		for k := max(0, i-context); k <= min(len(lines)-1, i+context); k++ {
+			show[k] = true
+		}
+	}
+
+	var b bytes.Buffer
+	gap := false
+	for i, l := range lines {
+		if !show[i] {
+			gap = true
+			continue
+		}
+		if gap && b.Len() > 0 {
+			b.WriteString("...\n")
+		}
+		gap = false
+		b.WriteByte(l.op)
+		b.WriteByte(' ')
+		b.WriteString(l.text)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// diffSummary describes the first change in plain text.
+func diffSummary(lines []diffLine) string {
+	for _, l := range lines {
+		switch l.op {
+		case '+':
+			return truncate("Added: "+l.text, 200)
+		case '-':
+			return truncate("Removed: "+l.text, 200)
+		}
+	}
+	return ""
+}
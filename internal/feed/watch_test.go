@@ -0,0 +1,156 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+func TestWatcherCheck(t *testing.T) {
+	page := `<html><body><nav>Home | About</nav>
+<table class="pricing"><tr><td>Starter</td><td>$10</td></tr><tr><td>Team</td><td>$50</td></tr></table>
+<footer>Rendered at 10:00</footer></body></html>`
+	etag := `"v1"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, page)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	w := &Watcher{SnapshotDir: dir, Client: srv.Client()}
+	feed := model.Feed{
+		ID:    "pricing",
+		Name:  "Vendor pricing",
+		URL:   "watch:" + srv.URL,
+		Watch: &model.WatchOptions{Selector: "table.pricing"},
+	}
+
+	// The first check records a baseline without an article.
+	articles, cursor, err := w.Check(t.Context(), feed, store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 0 {
+		t.Errorf("first check returned %d articles, want 0", len(articles))
+	}
+
+	// 304 Not Modified: nothing to do, but the saved cursor lets the
+	// staged baseline become the snapshot.
+	if articles, _, err := w.Check(t.Context(), feed, cursor); err != nil || len(articles) != 0 {
+		t.Errorf("not-modified check = %d articles, err %v", len(articles), err)
+	}
+	snapshot, err := os.ReadFile(filepath.Join(dir, "pricing.txt"))
+	if err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+	if strings.Contains(string(snapshot), "Rendered at") {
+		t.Error("snapshot includes text outside the selector")
+	}
+
+	// A change outside the selector isn't news.
+	page = strings.Replace(page, "10:00", "11:00", 1)
+	etag = `"v2"`
+	if articles, cursor, err = w.Check(t.Context(), feed, cursor); err != nil || len(articles) != 0 {
+		t.Errorf("change outside selector = %d articles, err %v", len(articles), err)
+	}
+
+	// A price change is.
+	page = strings.Replace(page, "$50", "$65", 1)
+	etag = `"v3"`
+	articles, cursor, err = w.Check(t.Context(), feed, cursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 {
+		t.Fatalf("got %d articles, want 1", len(articles))
+	}
+	a := articles[0]
+	if a.Title != "Vendor pricing changed (+1 −1 lines)" {
+		t.Errorf("Title = %q", a.Title)
+	}
+	if !strings.Contains(a.Content, "- Team $50\n+ Team $65\n") {
+		t.Errorf("Content = %q", a.Content)
+	}
+	if a.Summary != "Removed: Team $50" || a.URL != srv.URL || a.NormalizedTitle != "" {
+		t.Errorf("Summary, URL, NormalizedTitle = %q, %q, %q", a.Summary, a.URL, a.NormalizedTitle)
+	}
+
+	// Reverting and then repeating the change is news both times.
+	seen := []model.Article{a}
+	for i, change := range [][2]string{{"$65", "$50"}, {"$50", "$65"}} {
+		page = strings.Replace(page, change[0], change[1], 1)
+		etag = fmt.Sprintf(`"v%d"`, 4+i)
+		articles, cursor, err = w.Check(t.Context(), feed, cursor)
+		if err != nil || len(articles) != 1 {
+			t.Fatalf("change to %s = %d articles, err %v", change[1], len(articles), err)
+		}
+		if isDuplicateFrom(model.SourceWatch, articles[0], seen, 30) {
+			t.Errorf("change to %s was deduped against an earlier change", change[1])
+		}
+		seen = append(seen, articles[0])
+	}
+}
+
+func TestWatcherCheck_UnsavedCursorKeepsSnapshot(t *testing.T) {
+	price := "$10"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<p>Starter %s</p>`, price)
+	}))
+	defer srv.Close()
+
+	w := &Watcher{SnapshotDir: t.TempDir(), Client: srv.Client()}
+	feed := model.Feed{ID: "x", Name: "X", URL: "watch:" + srv.URL}
+	_, saved, err := w.Check(t.Context(), feed, store.Cursor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The change is reported, but the cache holding it is never saved,
+	// so the next check starts from the same cursor.
+	price = "$12"
+	if articles, _, err := w.Check(t.Context(), feed, saved); err != nil || len(articles) != 1 {
+		t.Fatalf("change = %d articles, err %v", len(articles), err)
+	}
+	articles, _, err := w.Check(t.Context(), feed, saved)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(articles) != 1 || !strings.Contains(articles[0].Content, "+ Starter $12") {
+		t.Errorf("articles = %+v, want the unsaved change reported again", articles)
+	}
+}
+
+func TestWatcherCheck_SelectorMatchesNothing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<p>Hello</p>`)
+	}))
+	defer srv.Close()
+
+	w := &Watcher{SnapshotDir: t.TempDir(), Client: srv.Client()}
+	feed := model.Feed{ID: "x", Name: "X", URL: "watch:" + srv.URL, Watch: &model.WatchOptions{Selector: "#gone"}}
+	if _, _, err := w.Check(t.Context(), feed, store.Cursor{}); err == nil {
+		t.Error("expected error when the selector matches nothing")
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	a := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}
+	b := []string{"1", "2", "three", "4", "5", "6", "7", "8", "9", "10"}
+	got := formatDiff(diffLines(a, b), 1)
+	want := "  2\n- 3\n+ three\n  4\n...\n  9\n+ 10\n"
+	if got != want {
+		t.Errorf("formatDiff =\n%s\nwant\n%s", got, want)
+	}
+}
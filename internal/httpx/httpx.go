@@ -0,0 +1,104 @@
+// Package httpx holds what every outgoing API request shares: the
+// timeout, the User-Agent, a default client and JSON helpers. It's used
+// by the feed sources and by the dependency resolver alike.
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Timeout bounds each API request.
+const Timeout = 30 * time.Second
+
+// UserAgent identifies feeder to APIs that reject anonymous clients.
+const UserAgent = "feeder (+https://github.com/mayknxyz/my-feeder)"
+
+// defaultClient is used by callers that weren't given an HTTP client.
+// LEARN: Sharing one client lets its transport reuse connections;
+// a client per request would open a new one every time.
+var defaultClient = &http.Client{Timeout: Timeout}
+
+// Client returns c, or the shared default client if c is nil.
+func Client(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return defaultClient
+}
+
+// OrDefault returns base without a trailing slash, or def if base is
+// empty.
+func OrDefault(base, def string) string {
+	if base == "" {
+		return def
+	}
+	return strings.TrimSuffix(base, "/")
+}
+
+// GetJSON GETs url and decodes the JSON response into v. Any status
+// outside 2xx is an error that includes the start of the body, since
+// APIs usually explain themselves there.
+func GetJSON(ctx context.Context, client *http.Client, url string, header http.Header, v any) error {
+	return DoJSON(ctx, client, http.MethodGet, url, header, nil, v)
+}
+
+// PostJSON POSTs body encoded as JSON and decodes the response into v,
+// with the same error handling as GetJSON.
+func PostJSON(ctx context.Context, client *http.Client, url string, header http.Header, body, v any) error {
+	return DoJSON(ctx, client, http.MethodPost, url, header, body, v)
+}
+
+// DoJSON performs a JSON API request. A nil body sends no payload.
+func DoJSON(ctx context.Context, client *http.Client, method, url string, header http.Header, body, v any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request for %s: %w", url, err)
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
+	if err != nil {
+		return fmt.Errorf("building request for %s: %w", url, err)
+	}
+	for k, vals := range header {
+		req.Header[k] = vals
+	}
+	// WHY: Some APIs want their own media type in Accept, and a json:
+	// feed may need to pass as a browser, so the defaults only fill in
+	// what the caller left unset.
+	defaults := map[string]string{"Accept": "application/json", "User-Agent": UserAgent}
+	if body != nil {
+		defaults["Content-Type"] = "application/json"
+	}
+	for k, v := range defaults {
+		if req.Header.Get(k) == "" {
+			req.Header.Set(k, v)
+		}
+	}
+
+	resp, err := Client(client).Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("fetching %s: %s: %s", url, resp.Status, bytes.TrimSpace(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding %s: %w", url, err)
+	}
+	return nil
+}
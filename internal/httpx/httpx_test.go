@@ -0,0 +1,54 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetJSON_Headers(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		accept, ua string
+	}{
+		{"defaults", nil, "application/json", UserAgent},
+		{"caller's own", http.Header{
+			"Accept":     {"application/vnd.github+json"},
+			"User-Agent": {"Mozilla/5.0"},
+		}, "application/vnd.github+json", "Mozilla/5.0"},
+	}
+	for _, tt := range tests {
+		var v struct{}
+		if err := GetJSON(t.Context(), srv.Client(), srv.URL, tt.header, &v); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if a := got.Get("Accept"); a != tt.accept {
+			t.Errorf("%s: Accept = %q, want %q", tt.name, a, tt.accept)
+		}
+		if ua := got.Get("User-Agent"); ua != tt.ua {
+			t.Errorf("%s: User-Agent = %q, want %q", tt.name, ua, tt.ua)
+		}
+	}
+}
+
+func TestGetJSON_ErrorIncludesBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	var v struct{}
+	err := GetJSON(t.Context(), srv.Client(), srv.URL, nil, &v)
+	if err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("err = %v, want the response body in it", err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package jsonpath evaluates the small JSONPath subset feeds use to
+// pick values out of decoded JSON: "$.data.items[0].title", with
+// ['quoted keys'] and [*] wildcards. Filters, slices and recursive
+// descent are deliberately left out.
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Path is a parsed path expression.
+type Path struct {
+	raw   string
+	steps []step
+}
+
+// step is one hop down the document: an object key, an array index, or
+// every child at once.
+type step struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Parse parses a path. The leading "$" is optional, so "data.items" and
+// "$.data.items" are the same path; "$" or "" alone is the root.
+func Parse(s string) (Path, error) {
+	p := Path{raw: s}
+	rest, rooted := strings.CutPrefix(strings.TrimSpace(s), "$")
+
+	// WHY: A bare leading key ("data.items") reads naturally in TOML;
+	// treat it as if it had the "." that follows "$".
+	if !rooted && rest != "" && rest[0] != '.' && rest[0] != '[' {
+		rest = "." + rest
+	}
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[]")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return Path{}, fmt.Errorf("path %q: empty key", s)
+			}
+			if key == "*" {
+				p.steps = append(p.steps, step{wildcard: true})
+			} else {
+				p.steps = append(p.steps, step{key: key})
+			}
+			rest = rest[end:]
+		case '[':
+			st, n, err := parseBracket(rest)
+			if err != nil {
+				return Path{}, fmt.Errorf("path %q: %w", s, err)
+			}
+			p.steps = append(p.steps, st)
+			rest = rest[n:]
+		default:
+			return Path{}, fmt.Errorf("path %q: unexpected %q", s, rest[0])
+		}
+	}
+	return p, nil
+}
+
+// parseBracket parses a [n], [*], ['key'] or ["key"] selector at the
+// start of s and returns it with the number of bytes consumed.
+func parseBracket(s string) (step, int, error) {
+	if len(s) > 1 && (s[1] == '\'' || s[1] == '"') {
+		quote := s[1]
+		end := strings.IndexByte(s[2:], quote)
+		if end < 0 || len(s) < end+4 || s[end+3] != ']' {
+			return step{}, 0, fmt.Errorf("unterminated quoted key")
+		}
+		return step{key: s[2 : end+2]}, end + 4, nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return step{}, 0, fmt.Errorf("missing ]")
+	}
+	inner := strings.TrimSpace(s[1:end])
+	if inner == "*" {
+		return step{wildcard: true}, end + 1, nil
+	}
+	n, err := strconv.Atoi(inner)
+	if err != nil {
+		return step{}, 0, fmt.Errorf("index %q is not a number", inner)
+	}
+	return step{index: n, isIndex: true}, end + 1, nil
+}
+
+// MustParse is like Parse but panics on error. For tests and constant
+// paths.
+func MustParse(s string) Path {
+	p, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the path as it was written.
+func (p Path) String() string {
+	return p.raw
+}
+
+// Eval returns every value the path reaches in doc, which is JSON
+// decoded into any (map[string]any, []any and scalars). Missing keys
+// and out-of-range indexes drop out rather than erroring, so a path
+// that matches nothing returns an empty slice.
+func (p Path) Eval(doc any) []any {
+	cur := []any{doc}
+	for _, st := range p.steps {
+		var next []any
+		for _, v := range cur {
+			next = append(next, st.apply(v)...)
+		}
+		cur = next
+	}
+	return cur
+}
+
+// First returns the first value the path reaches in doc.
+func (p Path) First(doc any) (any, bool) {
+	vals := p.Eval(doc)
+	if len(vals) == 0 {
+		return nil, false
+	}
+	return vals[0], true
+}
+
+// apply takes one step from v.
+func (st step) apply(v any) []any {
+	switch node := v.(type) {
+	case map[string]any:
+		if st.wildcard {
+			// LEARN: Map iteration order is random in Go; sort the keys
+			// so a wildcard over an object is deterministic.
+			keys := make([]string, 0, len(node))
+			for k := range node {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			out := make([]any, 0, len(keys))
+			for _, k := range keys {
+				out = append(out, node[k])
+			}
+			return out
+		}
+		if st.isIndex {
+			return nil
+		}
+		if child, ok := node[st.key]; ok {
+			return []any{child}
+		}
+	case []any:
+		if st.wildcard {
+			return node
+		}
+		if !st.isIndex {
+			return nil
+		}
+		i := st.index
+		if i < 0 {
+			i += len(node) // [-1] is the last element
+		}
+		if i >= 0 && i < len(node) {
+			return []any{node[i]}
+		}
+	}
+	return nil
+}
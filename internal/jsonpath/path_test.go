@@ -0,0 +1,72 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const doc = `{
+	"data": {
+		"items": [
+			{"id": 1, "title": "First", "tags": ["a", "b"]},
+			{"id": 2, "title": "Second", "meta": {"link.url": "https://example.com/2"}}
+		]
+	},
+	"groups": {"b": {"n": 2}, "a": {"n": 1}}
+}`
+
+func TestEval(t *testing.T) {
+	var v any
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want []any
+	}{
+		{"$.data.items[0].title", []any{"First"}},
+		{"data.items[1].title", []any{"Second"}},
+		{"$.data.items[*].title", []any{"First", "Second"}},
+		{"$.data.items.*.id", []any{1.0, 2.0}},
+		{"$.data.items[-1].id", []any{2.0}},
+		{"$.data.items[0].tags[1]", []any{"b"}},
+		{`$.data.items[1].meta['link.url']`, []any{"https://example.com/2"}},
+		{`$.data.items[1]["meta"]["link.url"]`, []any{"https://example.com/2"}},
+		{"$.groups[*].n", []any{1.0, 2.0}}, // sorted by key
+		{"$.data.items[5].title", nil},
+		{"$.data.missing", nil},
+		{"$.data.items.title", nil}, // keys don't apply to arrays
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.path)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.path, err)
+			continue
+		}
+		if got := p.Eval(v); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Eval(%q) = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+
+	// The root path returns the document itself.
+	if got := MustParse("$").Eval(v); len(got) != 1 || !reflect.DeepEqual(got[0], v) {
+		t.Errorf("Eval($) = %#v, want the whole document", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, path := range []string{
+		"$.data..items",
+		"$.items[",
+		"$.items[x]",
+		"$.items['open]",
+		"$items",
+		"$.a]",
+	} {
+		if _, err := Parse(path); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", path)
+		}
+	}
+}
@@ -3,15 +3,125 @@
 // application. They are plain data holders with no business logic.
 package model
 
-import "time"
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Source kinds, selected by a prefix on the feed URL ("github:owner/repo").
+// A URL without a recognised prefix is fetched as RSS/Atom.
+const (
+	SourceRSS           = "rss"
+	SourceGitHub        = "github"
+	SourceGitHubTags    = "github-tags"
+	SourceGitHubSearch  = "github-search"
+	SourceGitHubCommits = "github-commits"
+	SourceGitLab        = "gitlab"
+	SourceGitea         = "gitea"
+	SourceGitHubStars   = "github-stars"
+	SourceGoMod         = "gomod"
+	SourceNPM           = "npm"
+	SourcePyPI          = "pypi"
+	SourceCrates        = "crates"
+	SourceOCI           = "oci"
+	SourceOSV           = "osv"
+	SourceMastodon      = "mastodon"
+	SourceHN            = "hn"
+	SourceLobsters      = "lobsters"
+	SourceExec          = "exec"
+	SourceFile          = "file"
+	SourceDir           = "dir"
+	SourceWatch         = "watch"
+	SourceScrape        = "scrape"
+	SourceJSON          = "json"
+	SourceICS           = "ics"
+	SourceMail          = "mail"
+	SourceGemini        = "gemini"
+)
+
+// sourcePrefixes lists the URL prefixes that select a non-RSS source.
+var sourcePrefixes = map[string]bool{
+	SourceGitHub:        true,
+	SourceGitHubTags:    true,
+	SourceGitHubSearch:  true,
+	SourceGitHubCommits: true,
+	SourceGitLab:        true,
+	SourceGitea:         true,
+	SourceGitHubStars:   true,
+	SourceGoMod:         true,
+	SourceNPM:           true,
+	SourcePyPI:          true,
+	SourceCrates:        true,
+	SourceOCI:           true,
+	SourceOSV:           true,
+	SourceMastodon:      true,
+	SourceHN:            true,
+	SourceLobsters:      true,
+	SourceExec:          true,
+	SourceFile:          true,
+	SourceDir:           true,
+	SourceWatch:         true,
+	SourceScrape:        true,
+	SourceJSON:          true,
+	SourceICS:           true,
+	SourceMail:          true,
+	SourceGemini:        true,
+}
 
 // Feed represents a single feed source from the config file.
 // It can be an RSS/Atom feed or a GitHub release tracker.
 type Feed struct {
+	ID            string `toml:"id,omitempty" json:"id,omitempty"`
 	Name          string `toml:"name" json:"name"`
 	URL           string `toml:"url" json:"url"`
 	Tag           string `toml:"tag,omitempty" json:"tag,omitempty"`
 	RetentionDays *int   `toml:"retention_days,omitempty" json:"retention_days,omitempty"`
+
+	// Release filtering, for sources whose articles carry a version.
+	Prereleases *bool  `toml:"prereleases,omitempty" json:"prereleases,omitempty"`
+	Semver      string `toml:"semver,omitempty" json:"semver,omitempty"`
+	Only        string `toml:"only,omitempty" json:"only,omitempty"`
+	TagRegex    string `toml:"tag_regex,omitempty" json:"tag_regex,omitempty"`
+
+	// Exclude lists "owner/repo" patterns (path.Match globs) to leave
+	// out when a feed group expands into per-repo feeds.
+	Exclude []string `toml:"exclude,omitempty" json:"exclude,omitempty"`
+
+	// Score thresholds for link aggregators (hn:, lobsters:). A story
+	// must reach both to appear.
+	MinPoints   int `toml:"min_points,omitempty" json:"min_points,omitempty"`
+	MinComments int `toml:"min_comments,omitempty" json:"min_comments,omitempty"`
+
+	// Exec configures how an exec: feed's command runs.
+	Exec *ExecOptions `toml:"exec,omitempty" json:"exec,omitempty"`
+
+	// Watch narrows what a watch: feed compares.
+	Watch *WatchOptions `toml:"watch,omitempty" json:"watch,omitempty"`
+
+	// Scrape tells a scrape: feed where articles are on the page.
+	Scrape *ScrapeOptions `toml:"scrape,omitempty" json:"scrape,omitempty"`
+
+	// JSON maps a json: feed's response onto articles.
+	JSON *JSONOptions `toml:"json,omitempty" json:"json,omitempty"`
+
+	// ICS sets how far ahead an ics: feed expands recurring events.
+	ICS *ICSOptions `toml:"ics,omitempty" json:"ics,omitempty"`
+
+	// Mail picks which messages in a mail: feed's mailbox are articles.
+	Mail *MailOptions `toml:"mail,omitempty" json:"mail,omitempty"`
+}
+
+// ExecOptions is the [feeds.exec] block of an exec: feed.
+type ExecOptions struct {
+	// Args are appended to the command from the URL, for arguments
+	// that contain spaces.
+	Args []string `toml:"args,omitempty" json:"args,omitempty"`
+	// Timeout is a Go duration ("30s", "2m").
+	Timeout string            `toml:"timeout,omitempty" json:"timeout,omitempty"`
+	Env     map[string]string `toml:"env,omitempty" json:"env,omitempty"`
+	Dir     string            `toml:"dir,omitempty" json:"dir,omitempty"`
 }
 
 // IsGitHub reports whether this feed tracks GitHub releases
@@ -32,11 +142,104 @@ func (f Feed) GitHubRepo() string {
 	return f.URL[7:]
 }
 
+// IsGroup reports whether this feed is a group that expands into
+// several feeds at refresh time, rather than a feed of its own.
+func (f Feed) IsGroup() bool {
+	return f.Source() == SourceGitHubStars
+}
+
+// Source reports which kind of source the feed's URL points at.
+func (f Feed) Source() string {
+	prefix, _, ok := strings.Cut(f.URL, ":")
+	if ok && sourcePrefixes[prefix] {
+		return prefix
+	}
+	return SourceRSS
+}
+
+// Target returns the URL with its source prefix removed, e.g.
+// "owner/repo" for "github-tags:owner/repo". RSS feeds return the
+// URL unchanged.
+func (f Feed) Target() string {
+	if f.Source() == SourceRSS {
+		return f.URL
+	}
+	_, rest, _ := strings.Cut(f.URL, ":")
+	return rest
+}
+
+// FeedIDFromURL derives the default feed ID from a feed URL. It is used
+// when the config doesn't set an explicit id, and to re-key caches that
+// were written before feeds had IDs.
+func FeedIDFromURL(url string) string {
+	// WHY: A short hash keeps cache keys filesystem- and JSON-friendly
+	// while staying stable for as long as the URL doesn't change. Feeds
+	// that need to survive a URL change set an explicit id instead.
+	h := sha256.Sum256([]byte(url))
+	return fmt.Sprintf("%x", h[:6])
+}
+
+// WatchOptions is the [feeds.watch] block of a watch: feed.
+type WatchOptions struct {
+	// Selector is a CSS selector; only matching elements are compared.
+	Selector string `toml:"selector,omitempty" json:"selector,omitempty"`
+}
+
+// ScrapeOptions is the [feeds.scrape] block of a scrape: feed. Item
+// selects one element per article; the other selectors run inside it.
+type ScrapeOptions struct {
+	Item  string `toml:"item" json:"item"`
+	Title string `toml:"title" json:"title"`
+	// Link defaults to the title element when it is a link, else the
+	// item's first link.
+	Link    string `toml:"link,omitempty" json:"link,omitempty"`
+	Date    string `toml:"date,omitempty" json:"date,omitempty"`
+	Summary string `toml:"summary,omitempty" json:"summary,omitempty"`
+	// DateFormat is a Go time layout ("January 2, 2006"). Without it,
+	// common ISO 8601 shapes are tried.
+	DateFormat string `toml:"date_format,omitempty" json:"date_format,omitempty"`
+}
+
+// JSONOptions is the [feeds.json] block of a json: feed. Items is a
+// path to the array of items; the field paths are relative to one item.
+// Paths use a JSONPath subset: "$.data.items", "links[0].href".
+type JSONOptions struct {
+	Items   string `toml:"items" json:"items"`
+	Title   string `toml:"title" json:"title"`
+	URL     string `toml:"url,omitempty" json:"url,omitempty"`
+	GUID    string `toml:"guid,omitempty" json:"guid,omitempty"`
+	Date    string `toml:"date,omitempty" json:"date,omitempty"`
+	Content string `toml:"content,omitempty" json:"content,omitempty"`
+	// DateFormat is a Go time layout for string dates. Numeric dates
+	// are read as Unix seconds, or milliseconds if they're too large.
+	DateFormat string `toml:"date_format,omitempty" json:"date_format,omitempty"`
+	// Headers are sent with the request. Values may reference
+	// environment variables ("Bearer $STATUS_TOKEN").
+	Headers map[string]string `toml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// ICSOptions is the [feeds.ics] block of an ics: feed.
+type ICSOptions struct {
+	// LookaheadDays bounds recurrence expansion; one-off events are
+	// shown however far ahead they are. Defaults to 90.
+	LookaheadDays int `toml:"lookahead_days,omitempty" json:"lookahead_days,omitempty"`
+}
+
+// MailOptions is the [feeds.mail] block of a mail: feed. A message
+// matching any From or ListID entry is included; with neither set,
+// every message in the mailbox is.
+type MailOptions struct {
+	// From lists sender address globs ("*@substack.com").
+	From []string `toml:"from,omitempty" json:"from,omitempty"`
+	// ListID lists substrings of the List-Id header to match.
+	ListID []string `toml:"list_id,omitempty" json:"list_id,omitempty"`
+}
+
 // Article represents a single entry from a feed (RSS item, Atom entry,
 // or GitHub release). This is the primary unit of content in the app.
 type Article struct {
 	GUID            string    `json:"guid"`
-	FeedURL         string    `json:"feed_url"`
+	FeedID          string    `json:"feed_id"`
 	Title           string    `json:"title"`
 	URL             string    `json:"url,omitempty"`
 	Author          string    `json:"author,omitempty"`
@@ -45,6 +248,33 @@ type Article struct {
 	PublishedAt     time.Time `json:"published_at"`
 	FetchedAt       time.Time `json:"fetched_at"`
 	NormalizedTitle string    `json:"normalized_title,omitempty"`
+
+	// EndsAt is set for articles about something with a duration, such
+	// as a calendar event. Retention counts from it instead of from
+	// PublishedAt, so events stay listed until they're over.
+	EndsAt time.Time `json:"ends_at,omitzero"`
+
+	// CommentsURL links to the discussion of a link-aggregator story,
+	// which is separate from URL, the story's target.
+	CommentsURL string `json:"comments_url,omitempty"`
+
+	// Version is set by release-like sources (the tag or version string)
+	// and is what per-feed release filters match against.
+	Version    string `json:"version,omitempty"`
+	Prerelease bool   `json:"prerelease,omitempty"`
+
+	// Enclosures are media files attached to the article, such as a
+	// podcast episode's audio.
+	Enclosures []Enclosure `json:"enclosures,omitempty"`
+}
+
+// Enclosure is a media file attached to an article. Length and Duration
+// are what the feed claims, and are zero when it doesn't say.
+type Enclosure struct {
+	URL      string        `json:"url"`
+	Type     string        `json:"type,omitempty"`
+	Length   int64         `json:"length,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
 }
 
 // Bookmark represents a saved article with optional user notes.
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package podcast
+
+import "os"
+
+// lockFile is a no-op where flock(2) isn't available; concurrent runs
+// there must not download the same episode.
+func lockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,321 @@
+// Package podcast finds podcast episodes among cached articles — those
+// with an audio or video enclosure — and downloads them into a folder
+// per feed. Interrupted downloads are resumed where they stopped.
+package podcast
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/mayknxyz/my-feeder/internal/httpx"
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// errLocked is returned when another download holds an episode's part
+// file.
+var errLocked = errors.New("already being downloaded")
+
+// maxNameLen bounds the length of a file or folder name, in runes,
+// leaving room for the date and extension under common filesystem limits.
+const maxNameLen = 120
+
+// Episode is an article with a media enclosure.
+type Episode struct {
+	Article   model.Article
+	Feed      string // feed name, used as the episode's folder
+	Enclosure model.Enclosure
+}
+
+// Episodes returns an episode for every article with an audio or video
+// enclosure, newest first. feedNames maps feed IDs to display names;
+// feeds missing from it are named by ID.
+func Episodes(articles []model.Article, feedNames map[string]string) []Episode {
+	var eps []Episode
+	for _, a := range articles {
+		for _, e := range a.Enclosures {
+			if !isMedia(e) {
+				continue
+			}
+			name := feedNames[a.FeedID]
+			if name == "" {
+				name = a.FeedID
+			}
+			eps = append(eps, Episode{Article: a, Feed: name, Enclosure: e})
+			break
+		}
+	}
+	slices.SortStableFunc(eps, func(a, b Episode) int {
+		return b.Article.PublishedAt.Compare(a.Article.PublishedAt)
+	})
+	return eps
+}
+
+// isMedia reports whether an enclosure is audio or video, going by its
+// declared type or, when there's none, its file extension.
+func isMedia(e model.Enclosure) bool {
+	t := e.Type
+	if t == "" {
+		t = mime.TypeByExtension(urlExt(e.URL))
+	}
+	return strings.HasPrefix(t, "audio/") || strings.HasPrefix(t, "video/")
+}
+
+// Path returns where an episode is saved under dir: a folder named for
+// its feed, and a file named for its date and title. A short hash of the
+// episode's GUID ends the name, so two episodes with the same title on
+// the same day don't overwrite each other.
+func Path(dir string, ep Episode) string {
+	name := ep.Article.PublishedAt.Format("2006-01-02") + " " + ep.Article.Title
+	sum := sha256.Sum256([]byte(ep.Article.GUID))
+	return filepath.Join(dir, safeName(ep.Feed), safeName(name)+" "+hex.EncodeToString(sum[:3])+extension(ep.Enclosure))
+}
+
+// safeName makes s usable as a file name on any common filesystem.
+func safeName(s string) string {
+	// WHY: Besides the path separator, these are the characters Windows
+	// rejects — downloads often end up on a synced or removable drive.
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return ' '
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxNameLen {
+		s = string(r[:maxNameLen])
+	}
+	s = strings.Trim(s, ". ")
+	if s == "" {
+		return "untitled"
+	}
+	return s
+}
+
+// extension picks a file extension for an enclosure: the one in its URL
+// if it looks like one, otherwise one registered for its media type.
+func extension(e model.Enclosure) string {
+	if ext := urlExt(e.URL); ext != "" {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(e.Type); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// urlExt returns the extension of a URL's path, ignoring the query that
+// podcast hosts add for tracking. Anything that isn't a short run of
+// letters and digits isn't taken to be an extension.
+func urlExt(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	if len(ext) < 2 || len(ext) > 5 {
+		return ""
+	}
+	for _, r := range ext[1:] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return ext
+}
+
+// Result is the outcome of downloading one episode.
+type Result struct {
+	Episode Episode
+	Path    string
+	Bytes   int64 // bytes transferred by this run
+	Resumed bool  // continued a partial download
+	Skipped bool  // the file was already there
+	// Cancelled means the context was done before the download started,
+	// so nothing was written; Err is the context's error.
+	Cancelled bool
+	Err       error
+}
+
+// Downloader downloads episodes into Dir, Concurrency at a time.
+type Downloader struct {
+	Dir         string
+	Concurrency int
+	Client      *http.Client
+
+	// Done, if set, is called as each download finishes, but not for
+	// those cancelled before they started. Calls are serialised, so it
+	// may update shared state without locking.
+	Done func(Result)
+}
+
+// Download fetches every episode in the queue and returns the results
+// in queue order. A failed download keeps what it received, and the
+// next Download of the same episode continues from there.
+func (d *Downloader) Download(ctx context.Context, queue []Episode) []Result {
+	results := make([]Result, len(queue))
+
+	// LEARN: The same buffered-channel semaphore the feed fetcher uses
+	// bounds how many downloads run at once.
+	sem := make(chan struct{}, max(d.Concurrency, 1))
+	var (
+		wg     sync.WaitGroup
+		doneMu sync.Mutex
+	)
+	for i, ep := range queue {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// WHY: After Ctrl-C, episodes still waiting for a slot
+			// shouldn't create folders or empty part files on the way
+			// out.
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+			}
+			if err := ctx.Err(); err != nil {
+				results[i] = Result{Episode: ep, Path: Path(d.Dir, ep), Cancelled: true, Err: err}
+				return
+			}
+
+			res := d.download(ctx, ep)
+			results[i] = res
+			if d.Done != nil {
+				doneMu.Lock()
+				d.Done(res)
+				doneMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// download fetches one episode into a ".part" file next to its final
+// path, asking the server for only the missing bytes when a previous
+// attempt left one behind, and renames it into place once complete.
+func (d *Downloader) download(ctx context.Context, ep Episode) Result {
+	res := Result{Episode: ep, Path: Path(d.Dir, ep)}
+	if _, err := os.Stat(res.Path); err == nil {
+		res.Skipped = true
+		return res
+	}
+	if err := os.MkdirAll(filepath.Dir(res.Path), 0o755); err != nil {
+		res.Err = fmt.Errorf("creating podcast folder: %w", err)
+		return res
+	}
+
+	part := res.Path + ".part"
+	f, err := os.OpenFile(part, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		res.Err = fmt.Errorf("opening %s: %w", part, err)
+		return res
+	}
+	defer f.Close()
+	// WHY: Another feeder run may be downloading the same episode; two
+	// writers appending to one part file would interleave their bytes.
+	if err := lockFile(f); err != nil {
+		res.Err = fmt.Errorf("locking %s: %w", part, err)
+		return res
+	}
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		res.Err = fmt.Errorf("reading %s: %w", part, err)
+		return res
+	}
+	// The lock may have been held by a run that has since finished.
+	if _, err := os.Stat(res.Path); err == nil {
+		if offset == 0 {
+			os.Remove(part)
+		}
+		res.Skipped = true
+		return res
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.Enclosure.URL, nil)
+	if err != nil {
+		res.Err = fmt.Errorf("building request for %s: %w", ep.Enclosure.URL, err)
+		return res
+	}
+	req.Header.Set("User-Agent", httpx.UserAgent)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	client := d.Client
+	if client == nil {
+		// No overall timeout: an hour-long episode on a slow link can
+		// legitimately take longer than any sensible API timeout.
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		res.Err = fmt.Errorf("downloading %s: %w", ep.Enclosure.URL, err)
+		return res
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			res.Err = fmt.Errorf("downloading %s: server resumed at the wrong offset (%s)", ep.Enclosure.URL, resp.Header.Get("Content-Range"))
+			return res
+		}
+		res.Resumed = true
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The part file already holds every byte; only the rename was
+		// missed.
+		res.Resumed = true
+		return finish(f, part, res)
+	case resp.StatusCode == http.StatusOK:
+		// WHY: A server that ignores Range sends the whole file again,
+		// so whatever was saved before is discarded.
+		if offset > 0 {
+			if err := f.Truncate(0); err != nil {
+				res.Err = fmt.Errorf("restarting %s: %w", part, err)
+				return res
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				res.Err = fmt.Errorf("restarting %s: %w", part, err)
+				return res
+			}
+		}
+	default:
+		res.Err = fmt.Errorf("downloading %s: %s", ep.Enclosure.URL, resp.Status)
+		return res
+	}
+
+	res.Bytes, err = io.Copy(f, resp.Body)
+	if err != nil {
+		res.Err = fmt.Errorf("downloading %s: %w", ep.Enclosure.URL, err)
+		return res
+	}
+	return finish(f, part, res)
+}
+
+// finish closes a completed part file and moves it to the result's path.
+func finish(f *os.File, part string, res Result) Result {
+	if err := f.Close(); err != nil {
+		res.Err = fmt.Errorf("writing %s: %w", part, err)
+		return res
+	}
+	if err := os.Rename(part, res.Path); err != nil {
+		res.Err = fmt.Errorf("renaming %s: %w", part, err)
+	}
+	return res
+}
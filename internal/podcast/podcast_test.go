@@ -0,0 +1,289 @@
+package podcast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// audio is the body every test episode downloads.
+var audio = bytes.Repeat([]byte("ID3 podcast audio "), 1000)
+
+func episode(url, title string) Episode {
+	return Episode{
+		Article: model.Article{
+			GUID:        url,
+			Title:       title,
+			PublishedAt: time.Date(2024, 3, 14, 6, 0, 0, 0, time.UTC),
+		},
+		Feed:      "Go Time",
+		Enclosure: model.Enclosure{URL: url, Type: "audio/mpeg"},
+	}
+}
+
+func TestEpisodes(t *testing.T) {
+	older := time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)
+	articles := []model.Article{
+		{GUID: "a", FeedID: "gotime", Title: "Older", PublishedAt: older, Enclosures: []model.Enclosure{
+			{URL: "https://cdn.example.com/cover.jpg", Type: "image/jpeg"},
+			{URL: "https://cdn.example.com/older.mp3", Type: "audio/mpeg"},
+		}},
+		{GUID: "b", FeedID: "blog", Title: "No enclosure", PublishedAt: newer},
+		{GUID: "c", FeedID: "other", Title: "Newer", PublishedAt: newer, Enclosures: []model.Enclosure{
+			{URL: "https://cdn.example.com/newer.m4a?tracking=1"},
+		}},
+	}
+
+	eps := Episodes(articles, map[string]string{"gotime": "Go Time"})
+	if len(eps) != 2 {
+		t.Fatalf("got %d episodes, want 2", len(eps))
+	}
+	if eps[0].Article.GUID != "c" || eps[0].Feed != "other" {
+		t.Errorf("first episode = %q from %q, want c from other", eps[0].Article.GUID, eps[0].Feed)
+	}
+	if eps[1].Enclosure.URL != "https://cdn.example.com/older.mp3" || eps[1].Feed != "Go Time" {
+		t.Errorf("second episode = %q from %q", eps[1].Enclosure.URL, eps[1].Feed)
+	}
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		feed, title, url, typ string
+		want                  string
+	}{
+		{"Go Time", "Episode 300: Looking back", "https://cdn.example.com/300.mp3?utm=x", "audio/mpeg",
+			"Go Time/2024-03-14 Episode 300 Looking back 82e50f.mp3"},
+		{"AC/DC fans", "Who? What?", "https://cdn.example.com/play", "audio/mpeg",
+			"AC DC fans/2024-03-14 Who What ecb5ab.mp3"},
+		{"..", "Trailer", "https://cdn.example.com/episode.v2-final", "audio/x-unknown",
+			"untitled/2024-03-14 Trailer 54d6a7"},
+	}
+	for _, tt := range tests {
+		ep := episode(tt.url, tt.title)
+		ep.Feed = tt.feed
+		ep.Enclosure.Type = tt.typ
+		got := Path("/podcasts", ep)
+		if want := filepath.Join("/podcasts", tt.want); got != want {
+			t.Errorf("Path(%q, %q) = %q, want %q", tt.feed, tt.title, got, want)
+		}
+	}
+
+	// Same feed, title and day, different episodes.
+	a, b := episode("https://cdn.example.com/a.mp3", "Bonus"), episode("https://cdn.example.com/b.mp3", "Bonus")
+	if Path("/podcasts", a) == Path("/podcasts", b) {
+		t.Errorf("two episodes share the path %q", Path("/podcasts", a))
+	}
+}
+
+func TestDownload(t *testing.T) {
+	var ranges []string
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		http.ServeContent(w, r, "ep.mp3", time.Time{}, bytes.NewReader(audio))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ep := episode(srv.URL+"/ep.mp3", "Resumed")
+	path := Path(dir, ep)
+
+	// A previous run stopped partway through.
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".part", audio[:5000], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var done []Result
+	d := &Downloader{Dir: dir, Concurrency: 2, Done: func(r Result) { done = append(done, r) }}
+	results := d.Download(context.Background(), []Episode{ep})
+
+	res := results[0]
+	if res.Err != nil {
+		t.Fatalf("Download: %v", res.Err)
+	}
+	if !res.Resumed || res.Bytes != int64(len(audio)-5000) {
+		t.Errorf("Resumed, Bytes = %v, %d, want true, %d", res.Resumed, res.Bytes, len(audio)-5000)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=5000-" {
+		t.Errorf("Range headers = %q, want [bytes=5000-]", ranges)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading download: %v", err)
+	}
+	if !bytes.Equal(got, audio) {
+		t.Errorf("downloaded %d bytes, want the %d-byte file intact", len(got), len(audio))
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Errorf("part file left behind: %v", err)
+	}
+	if len(done) != 1 || done[0].Path != path {
+		t.Errorf("Done called with %+v", done)
+	}
+
+	// Running again finds the file and downloads nothing.
+	results = d.Download(context.Background(), []Episode{ep})
+	if !results[0].Skipped || len(ranges) != 1 {
+		t.Errorf("second run: Skipped = %v after %d requests, want true after 1", results[0].Skipped, len(ranges))
+	}
+}
+
+func TestDownload_RangeIgnored(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(audio)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ep := episode(srv.URL+"/ep.mp3", "Restarted")
+	path := Path(dir, ep)
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	if err := os.WriteFile(path+".part", []byte("stale bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res := (&Downloader{Dir: dir}).Download(context.Background(), []Episode{ep})[0]
+	if res.Err != nil || res.Resumed {
+		t.Fatalf("Err, Resumed = %v, %v, want nil, false", res.Err, res.Resumed)
+	}
+	got, _ := os.ReadFile(path)
+	if !bytes.Equal(got, audio) {
+		t.Errorf("downloaded %d bytes, want %d without the stale prefix", len(got), len(audio))
+	}
+}
+
+func TestDownload_PartLocked(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write(audio)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ep := episode(srv.URL+"/ep.mp3", "Contended")
+	path := Path(dir, ep)
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	f, err := os.OpenFile(path+".part", os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("no file locking on " + runtime.GOOS)
+	}
+
+	res := (&Downloader{Dir: dir}).Download(context.Background(), []Episode{ep})[0]
+	if !errors.Is(res.Err, errLocked) {
+		t.Errorf("Err = %v, want errLocked", res.Err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("made %d requests for a locked episode", requests.Load())
+	}
+}
+
+func TestDownload_Cancelled(t *testing.T) {
+	started := make(chan struct{})
+	var once sync.Once
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(started) })
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	dir := t.TempDir()
+	a, b := episode(srv.URL+"/a.mp3", "A"), episode(srv.URL+"/b.mp3", "B")
+	a.Feed, b.Feed = "Show A", "Show B"
+	var done []Result
+	d := &Downloader{Dir: dir, Concurrency: 1, Done: func(r Result) { done = append(done, r) }}
+	results := d.Download(ctx, []Episode{a, b})
+
+	// One download got the slot and was interrupted; the other never
+	// started.
+	if results[0].Cancelled == results[1].Cancelled {
+		t.Fatalf("Cancelled = %v, %v, want exactly one", results[0].Cancelled, results[1].Cancelled)
+	}
+	for _, res := range results {
+		if res.Err == nil {
+			t.Errorf("%s: no error after cancelling", res.Episode.Article.Title)
+		}
+		if !res.Cancelled {
+			continue
+		}
+		if !errors.Is(res.Err, context.Canceled) {
+			t.Errorf("%s: Err = %v, want context.Canceled", res.Episode.Article.Title, res.Err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, res.Episode.Feed)); !os.IsNotExist(err) {
+			t.Errorf("cancelled episode touched the disk: %v", err)
+		}
+	}
+	if len(done) != 1 || done[0].Cancelled {
+		t.Errorf("Done called with %+v, want only the started download", done)
+	}
+}
+
+func TestDownload_Concurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, "/missing.mp3") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(audio[:100])
+	}))
+	defer srv.Close()
+
+	var queue []Episode
+	for _, name := range []string{"a", "b", "c", "d", "missing"} {
+		queue = append(queue, episode(srv.URL+"/"+name+".mp3", "Episode "+name))
+	}
+	results := (&Downloader{Dir: t.TempDir(), Concurrency: 2}).Download(context.Background(), queue)
+
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrent downloads = %d, want at most 2", p)
+	}
+	for i, res := range results {
+		if res.Episode.Article.Title != queue[i].Article.Title {
+			t.Errorf("results[%d] is for %q, want queue order", i, res.Episode.Article.Title)
+		}
+	}
+	if err := results[4].Err; err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing episode error = %v, want a 404", err)
+	}
+}
@@ -0,0 +1,180 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+// Constraint is a parsed semver range such as ">=1.20, <2". Comma- or
+// space-separated terms must all hold; "||" separates alternatives.
+type Constraint struct {
+	alternatives [][]term
+}
+
+// term is a single comparison like ">=1.20".
+type term struct {
+	op      string
+	version Version
+}
+
+// ParseConstraint parses a semver range. Supported operators are =, !=,
+// >, >=, < and <=; a bare version means =.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{}
+	for _, alt := range strings.Split(s, "||") {
+		// Allow both ">=1.20, <2" and ">= 1.20 < 2".
+		fields := strings.Fields(strings.ReplaceAll(alt, ",", " "))
+		var terms []term
+		for i := 0; i < len(fields); i++ {
+			f := fields[i]
+			op := strings.TrimRight(f, "0123456789.-+abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZv")
+			rest := f[len(op):]
+			if rest == "" && i+1 < len(fields) {
+				i++
+				rest = fields[i]
+			}
+			switch op {
+			case "", "=", "==":
+				op = "="
+			case "!=", ">", ">=", "<", "<=":
+			default:
+				return nil, fmt.Errorf("semver %q: unknown operator %q", s, op)
+			}
+			v, ok := Parse(rest)
+			if !ok {
+				return nil, fmt.Errorf("semver %q: invalid version %q", s, rest)
+			}
+			terms = append(terms, term{op: op, version: v})
+		}
+		if len(terms) == 0 {
+			return nil, fmt.Errorf("semver %q: empty constraint", s)
+		}
+		c.alternatives = append(c.alternatives, terms)
+	}
+	return c, nil
+}
+
+// Allows reports whether v satisfies the constraint.
+func (c *Constraint) Allows(v Version) bool {
+	for _, terms := range c.alternatives {
+		ok := true
+		for _, t := range terms {
+			if !t.allows(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (t term) allows(v Version) bool {
+	c := Compare(v, t.version)
+	switch t.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	default: // "<="
+		return c <= 0
+	}
+}
+
+// Filter decides which releases a feed wants, built from the feed's
+// prereleases, semver, only and tag_regex options.
+type Filter struct {
+	prereleases bool
+	constraint  *Constraint
+	kinds       map[Kind]bool
+	tagRegex    *regexp.Regexp
+}
+
+// NewFilter builds the release filter for a feed. Returns nil if the
+// feed sets no filtering options, so callers can skip filtering.
+func NewFilter(feed model.Feed) (*Filter, error) {
+	if feed.Prereleases == nil && feed.Semver == "" && feed.Only == "" && feed.TagRegex == "" {
+		return nil, nil
+	}
+
+	f := &Filter{prereleases: true}
+	if feed.Prereleases != nil {
+		f.prereleases = *feed.Prereleases
+	}
+	if feed.Semver != "" {
+		c, err := ParseConstraint(feed.Semver)
+		if err != nil {
+			return nil, err
+		}
+		f.constraint = c
+	}
+	if feed.Only != "" {
+		f.kinds = make(map[Kind]bool)
+		for _, k := range strings.FieldsFunc(feed.Only, func(r rune) bool { return r == '|' || r == ',' || r == ' ' }) {
+			switch kind := Kind(k); kind {
+			case Major, Minor, Patch:
+				f.kinds[kind] = true
+			default:
+				return nil, fmt.Errorf("only %q: unknown release kind %q (want major, minor or patch)", feed.Only, k)
+			}
+		}
+	}
+	if feed.TagRegex != "" {
+		re, err := regexp.Compile(feed.TagRegex)
+		if err != nil {
+			return nil, fmt.Errorf("tag_regex %q: %w", feed.TagRegex, err)
+		}
+		f.tagRegex = re
+	}
+	return f, nil
+}
+
+// Allows reports whether a release with the given tag passes the filter.
+// prerelease is the source's own prerelease flag, if it has one; tags
+// with a prerelease suffix count as prereleases either way.
+func (f *Filter) Allows(tag string, prerelease bool) bool {
+	if f.tagRegex != nil && !f.tagRegex.MatchString(tag) {
+		return false
+	}
+
+	v, ok := Parse(tag)
+	if !f.prereleases && (prerelease || (ok && v.IsPrerelease())) {
+		return false
+	}
+
+	// WHY: A tag we can't read a version from ("nightly", "latest")
+	// can't satisfy a range or a kind, so version options exclude it.
+	if f.constraint != nil && (!ok || !f.constraint.Allows(v)) {
+		return false
+	}
+	if f.kinds != nil && (!ok || !f.kinds[v.Kind()]) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the articles that pass the filter. Articles without a
+// version aren't releases and always pass.
+func (f *Filter) Apply(articles []model.Article) []model.Article {
+	if f == nil {
+		return articles
+	}
+	kept := articles[:0]
+	for _, a := range articles {
+		if a.Version == "" || f.Allows(a.Version, a.Prerelease) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
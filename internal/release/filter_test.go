@@ -0,0 +1,118 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/mayknxyz/my-feeder/internal/model"
+)
+
+func TestConstraint_Allows(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{">=1.20, <2", "1.20.0", true},
+		{">=1.20, <2", "1.24.3", true},
+		{">=1.20, <2", "1.19.9", false},
+		{">=1.20, <2", "2.0.0", false},
+		{">= 1.20 < 2", "1.21.0", true},
+		{"1.2.3", "1.2.3", true},
+		{"!=1.2.3", "1.2.3", false},
+		{"<1 || >=3", "0.9.0", true},
+		{"<1 || >=3", "2.0.0", false},
+		{"<1 || >=3", "3.1.0", true},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.constraint, err)
+		}
+		v, _ := Parse(tt.version)
+		if got := c.Allows(v); got != tt.want {
+			t.Errorf("%q allows %s = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	for _, s := range []string{"", "~>1.0", ">=banana", "||"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) should fail", s)
+		}
+	}
+}
+
+func TestNewFilter_NoOptions(t *testing.T) {
+	f, err := NewFilter(model.Feed{Name: "plain"})
+	if err != nil || f != nil {
+		t.Errorf("NewFilter() = %v, %v; want nil, nil", f, err)
+	}
+	articles := []model.Article{{Version: "v1.0.0-rc.1"}}
+	if got := f.Apply(articles); len(got) != 1 {
+		t.Error("nil filter should keep everything")
+	}
+}
+
+func TestFilter_Apply(t *testing.T) {
+	no := false
+	f, err := NewFilter(model.Feed{
+		Prereleases: &no,
+		Semver:      ">=1.20, <2",
+		Only:        "major|minor",
+		TagRegex:    `^v`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	articles := []model.Article{
+		{Title: "keep minor", Version: "v1.21.0"},
+		{Title: "drop patch", Version: "v1.21.3"},
+		{Title: "drop rc", Version: "v1.22.0-rc.1"},
+		{Title: "drop flagged prerelease", Version: "v1.23.0", Prerelease: true},
+		{Title: "drop out of range", Version: "v2.0.0"},
+		{Title: "drop tag pattern", Version: "1.24.0"},
+		{Title: "drop unversioned tag", Version: "vnightly"},
+		{Title: "keep non-release"},
+	}
+
+	got := f.Apply(articles)
+	if len(got) != 2 || got[0].Title != "keep minor" || got[1].Title != "keep non-release" {
+		var titles []string
+		for _, a := range got {
+			titles = append(titles, a.Title)
+		}
+		t.Errorf("kept %v, want [keep minor keep non-release]", titles)
+	}
+}
+
+func TestFilter_UnversionedTagsPassPrereleaseFilter(t *testing.T) {
+	no := false
+	f, err := NewFilter(model.Feed{Prereleases: &no})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tag := range []string{"1.2.3.4", "release-2024-01-05"} {
+		if !f.Allows(tag, false) {
+			t.Errorf("Allows(%q) = false, want tags that aren't versions kept", tag)
+		}
+	}
+	if f.Allows("v1.2.3-rc.1", false) {
+		t.Error("Allows(v1.2.3-rc.1) = true, want prereleases dropped")
+	}
+}
+
+func TestNewFilter_Invalid(t *testing.T) {
+	bad := []model.Feed{
+		{Semver: ">>1"},
+		{Only: "major|huge"},
+		{TagRegex: "v(["},
+	}
+	for _, feed := range bad {
+		if _, err := NewFilter(feed); err == nil {
+			t.Errorf("NewFilter(%+v) should fail", feed)
+		}
+	}
+}
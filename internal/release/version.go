@@ -0,0 +1,207 @@
+// Package release parses version tags and decides which releases a feed
+// wants to see. It backs the per-feed prerelease, semver, "only" and
+// tag_regex options, and applies to any source that reports versions.
+package release
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Kind classifies a version by which component it bumps.
+type Kind string
+
+// Release kinds, as used by a feed's `only` option.
+const (
+	Major Kind = "major"
+	Minor Kind = "minor"
+	Patch Kind = "patch"
+)
+
+// Version is a parsed, semver-like version. Missing minor or patch
+// components parse as zero, so "v2" and "1.20" are valid.
+type Version struct {
+	Major, Minor, Patch int
+	Pre                 string
+}
+
+// Parse extracts a version from a tag. It is deliberately lenient
+// about the shapes real projects use: "v1.2.3", "1.2", "go1.24rc1",
+// "tokio-1.40.0" and "v2.0.0-beta.1" all parse. Reports false if the
+// tag contains no version number at all, or one followed by something
+// other than a prerelease or variant suffix.
+func Parse(tag string) (Version, bool) {
+	// WHY: Skip any name prefix ("go", "tokio-", "release/") by starting
+	// at the first digit. Tags like "nightly" have none and don't parse.
+	start := strings.IndexFunc(tag, isDigit)
+	if start < 0 {
+		return Version{}, false
+	}
+	s := tag[start:]
+
+	var v Version
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, n := range nums {
+		end := 0
+		for end < len(s) && isDigit(rune(s[end])) {
+			end++
+		}
+		if end == 0 {
+			return Version{}, false
+		}
+		*n, _ = strconv.Atoi(s[:end])
+		s = s[end:]
+		if i < len(nums)-1 && len(s) > 1 && s[0] == '.' && isDigit(rune(s[1])) {
+			s = s[1:]
+			continue
+		}
+		break
+	}
+
+	// Build metadata never affects precedence.
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if !validSuffix(s) {
+		return Version{}, false
+	}
+	v.Pre = strings.TrimLeft(s, "-.")
+	return v, true
+}
+
+// validSuffix reports whether what follows a version's numbers reads as
+// a prerelease or variant ("-rc.1", "beta2", "-alpine", "-1").
+//
+// WHY: A fourth component ("1.2.3.4") or a date ("release-2024-01-05")
+// would otherwise parse as a prerelease and be dropped by the default
+// filter. Such tags aren't versions we can order, so they don't parse.
+func validSuffix(s string) bool {
+	if s == "" {
+		return true
+	}
+	dash := s[0] == '-'
+	if dash || s[0] == '.' {
+		s = s[1:]
+	}
+	switch {
+	case s == "":
+		return false
+	case !isDigit(rune(s[0])):
+		return true
+	case !dash:
+		return false
+	}
+	// After a dash, a numeric identifier as in "1.0.0-1", without a
+	// leading zero.
+	id, _, _ := strings.Cut(s, ".")
+	if strings.TrimFunc(id, isDigit) != "" {
+		return false
+	}
+	return id == "0" || id[0] != '0'
+}
+
+// prereleaseWords are the words a prerelease suffix starts with, digits
+// aside ("rc1", "b2", "M3"). Any other word names a build variant, as in
+// the image tag "1.25.3-alpine", and leaves the version a release.
+var prereleaseWords = map[string]bool{
+	"a": true, "alpha": true, "b": true, "beta": true, "c": true, "rc": true, "cr": true,
+	"pre": true, "preview": true, "dev": true, "ea": true, "m": true, "milestone": true,
+	"canary": true, "experimental": true, "insiders": true, "nightly": true,
+	"snapshot": true, "unstable": true,
+}
+
+// IsPrerelease reports whether the version carries a prerelease suffix
+// such as "-rc.1", "beta2" or "-nightly". A variant suffix such as
+// "-alpine" or "-slim" doesn't count.
+func (v Version) IsPrerelease() bool {
+	if v.Pre == "" {
+		return false
+	}
+	word, _, _ := strings.Cut(v.Pre, ".")
+	word = strings.ToLower(strings.TrimRightFunc(word, isDigit))
+	// A numeric identifier, as in "1.0.0-1", is a prerelease per semver.
+	return word == "" || prereleaseWords[word]
+}
+
+// Kind reports which component this version bumps: x.0.0 is a major
+// release, x.y.0 a minor one, anything else a patch.
+func (v Version) Kind() Kind {
+	switch {
+	case v.Minor == 0 && v.Patch == 0:
+		return Major
+	case v.Patch == 0:
+		return Minor
+	default:
+		return Patch
+	}
+}
+
+// String formats the version in canonical semver form.
+func (v Version) String() string {
+	s := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Compare returns -1, 0 or +1 depending on whether a sorts before, the
+// same as, or after b, following semver precedence: a prerelease sorts
+// before the release it precedes. Variants of a release compare equal
+// to it.
+func Compare(a, b Version) int {
+	for _, d := range []int{a.Major - b.Major, a.Minor - b.Minor, a.Patch - b.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	aPre, bPre := a.IsPrerelease(), b.IsPrerelease()
+	switch {
+	case !aPre && !bPre:
+		return 0
+	case !aPre:
+		return 1
+	case !bPre:
+		return -1
+	}
+	return comparePre(a.Pre, b.Pre)
+}
+
+// comparePre compares dot-separated prerelease identifiers: numeric
+// ones numerically, others lexically, numeric before alphanumeric.
+func comparePre(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,101 @@
+package release
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Version
+		ok   bool
+	}{
+		{"v1.2.3", Version{1, 2, 3, ""}, true},
+		{"1.20", Version{1, 20, 0, ""}, true},
+		{"v2", Version{2, 0, 0, ""}, true},
+		{"go1.24rc1", Version{1, 24, 0, "rc1"}, true},
+		{"tokio-1.40.0", Version{1, 40, 0, ""}, true},
+		{"v2.0.0-beta.1", Version{2, 0, 0, "beta.1"}, true},
+		{"v1.0.0+build.5", Version{1, 0, 0, ""}, true},
+		{"v1.0.0-1", Version{1, 0, 0, "1"}, true},
+		{"1.25.3-alpine", Version{1, 25, 3, "alpine"}, true},
+		{"1.2.3.4", Version{}, false},
+		{"release-2024-01-05", Version{}, false},
+		{"nightly", Version{}, false},
+		{"", Version{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.tag)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v; want %+v, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.25.3-alpine", "1.25.3", 0},
+		{"1.25.3-alpine", "1.25.3-rc.1", 1},
+	}
+
+	for _, tt := range tests {
+		a, _ := Parse(tt.a)
+		b, _ := Parse(tt.b)
+		if got := Compare(a, b); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestVersion_IsPrerelease(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{"v1.2.3", false},
+		{"v2.0.0-rc.1", true},
+		{"go1.24rc1", true},
+		{"1.0b2", true},
+		{"v3.0.0-SNAPSHOT", true},
+		{"1.0.0-1", true},
+		{"1.25.3-alpine", false},
+		{"3.12-alpine3.19", false},
+		{"17-jdk-slim", false},
+	}
+
+	for _, tt := range tests {
+		v, _ := Parse(tt.tag)
+		if got := v.IsPrerelease(); got != tt.want {
+			t.Errorf("Parse(%q).IsPrerelease() = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestVersion_Kind(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Kind
+	}{
+		{"v2.0.0", Major},
+		{"v1.21.0", Minor},
+		{"v1.21.4", Patch},
+		{"go1.24", Minor},
+	}
+
+	for _, tt := range tests {
+		v, _ := Parse(tt.tag)
+		if got := v.Kind(); got != tt.want {
+			t.Errorf("Parse(%q).Kind() = %s, want %s", tt.tag, got, tt.want)
+		}
+	}
+}
@@ -4,16 +4,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/mayknxyz/my-feeder/internal/model"
 )
 
-// Cache holds fetched articles grouped by feed URL. This file is local
+// cacheVersion is the current cache file format. Version 1 keyed
+// articles by feed URL; version 2 keys them by feed ID.
+const cacheVersion = 2
+
+// Cache holds fetched articles grouped by feed ID. This file is local
 // and ephemeral — it can be deleted and rebuilt by re-fetching feeds.
 type Cache struct {
-	Version     int                    `json:"version"`
+	Version     int                        `json:"version"`
 	Articles    map[string][]model.Article `json:"articles"`
-	LastFetched map[string]string      `json:"last_fetched"`
+	LastFetched map[string]string          `json:"last_fetched"`
+	Cursors     map[string]Cursor          `json:"cursors,omitempty"`
+	Groups      map[string]Group           `json:"groups,omitempty"`
+}
+
+// Group is the cached expansion of a feed group, such as the repos a
+// GitHub user has starred, keyed by the group feed's ID.
+type Group struct {
+	Members   []string  `json:"members"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Cursor records where a source left off so the next refresh can fetch
+// incrementally. Which fields are used depends on the source; a zero
+// Cursor means "fetch from scratch".
+type Cursor struct {
+	ETag     string    `json:"etag,omitempty"`
+	LatestID string    `json:"latest_id,omitempty"`
+	Since    time.Time `json:"since,omitzero"`
+
+	// Seen maps what the source has already reported, such as tag
+	// names or advisory IDs, to a marker of the source's choosing. It
+	// serves sources whose new items don't all sort after a single
+	// high-water mark.
+	Seen map[string]string `json:"seen,omitempty"`
 }
 
 // LoadCache reads the cache file from disk. If the file doesn't exist,
@@ -22,7 +51,7 @@ type Cache struct {
 func LoadCache(path string) (*Cache, error) {
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
-		return newCache(), nil
+		return NewCache(), nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("reading cache file %s: %w", path, err)
@@ -40,6 +69,12 @@ func LoadCache(path string) (*Cache, error) {
 	if c.LastFetched == nil {
 		c.LastFetched = make(map[string]string)
 	}
+	if c.Cursors == nil {
+		c.Cursors = make(map[string]Cursor)
+	}
+	if c.Groups == nil {
+		c.Groups = make(map[string]Group)
+	}
 	return &c, nil
 }
 
@@ -48,10 +83,50 @@ func SaveCache(path string, cache *Cache) error {
 	return writeJSON(path, cache)
 }
 
-// ArticlesForFeed returns all cached articles for a given feed URL.
+// MigrateFeedKeys re-keys a version 1 cache from feed URLs to feed IDs,
+// so history carries over to the ID-keyed format. Keys that don't match
+// any configured feed get the default URL-derived ID. Reports whether
+// anything was migrated; caches already at the current version are
+// left alone.
+func (c *Cache) MigrateFeedKeys(feeds []model.Feed) bool {
+	if c.Version >= cacheVersion {
+		return false
+	}
+
+	ids := make(map[string]string, len(feeds))
+	for _, f := range feeds {
+		ids[f.URL] = f.ID
+	}
+	idFor := func(url string) string {
+		if id, ok := ids[url]; ok && id != "" {
+			return id
+		}
+		return model.FeedIDFromURL(url)
+	}
+
+	articles := make(map[string][]model.Article, len(c.Articles))
+	for url, list := range c.Articles {
+		id := idFor(url)
+		for i := range list {
+			list[i].FeedID = id
+		}
+		articles[id] = append(articles[id], list...)
+	}
+	lastFetched := make(map[string]string, len(c.LastFetched))
+	for url, ts := range c.LastFetched {
+		lastFetched[idFor(url)] = ts
+	}
+
+	c.Articles = articles
+	c.LastFetched = lastFetched
+	c.Version = cacheVersion
+	return true
+}
+
+// ArticlesForFeed returns all cached articles for a given feed ID.
 // Returns an empty slice if the feed has no cached articles.
-func (c *Cache) ArticlesForFeed(feedURL string) []model.Article {
-	articles, ok := c.Articles[feedURL]
+func (c *Cache) ArticlesForFeed(feedID string) []model.Article {
+	articles, ok := c.Articles[feedID]
 	if !ok {
 		return []model.Article{}
 	}
@@ -67,9 +142,9 @@ func (c *Cache) AllArticles() []model.Article {
 	return all
 }
 
-// SetArticles replaces all cached articles for a feed URL.
-func (c *Cache) SetArticles(feedURL string, articles []model.Article) {
-	c.Articles[feedURL] = articles
+// SetArticles replaces all cached articles for a feed ID.
+func (c *Cache) SetArticles(feedID string, articles []model.Article) {
+	c.Articles[feedID] = articles
 }
 
 // ArticleCount returns the total number of cached articles across all feeds.
@@ -81,11 +156,13 @@ func (c *Cache) ArticleCount() int {
 	return count
 }
 
-// newCache creates an empty cache with initialized maps.
-func newCache() *Cache {
+// NewCache creates an empty cache with initialized maps.
+func NewCache() *Cache {
 	return &Cache{
-		Version:     1,
+		Version:     cacheVersion,
 		Articles:    make(map[string][]model.Article),
 		LastFetched: make(map[string]string),
+		Cursors:     make(map[string]Cursor),
+		Groups:      make(map[string]Group),
 	}
 }
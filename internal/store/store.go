@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 )
 
 // State tracks which articles have been read. This file is designed to be
@@ -16,6 +17,11 @@ type State struct {
 	Version    int      `json:"version"`
 	Read       []string `json:"read"`
 	LastSynced string   `json:"last_synced,omitempty"`
+
+	// Downloaded and Played hold the GUIDs of podcast episodes fetched
+	// by the download queue and listened to.
+	Downloaded []string `json:"downloaded,omitempty"`
+	Played     []string `json:"played,omitempty"`
 }
 
 // LoadState reads the state file from disk. If the file doesn't exist,
@@ -73,6 +79,31 @@ func (s *State) MarkUnread(guid string) {
 	}
 }
 
+// IsDownloaded reports whether a podcast episode has been downloaded.
+func (s *State) IsDownloaded(guid string) bool {
+	return slices.Contains(s.Downloaded, guid)
+}
+
+// MarkDownloaded records that a podcast episode has been downloaded.
+func (s *State) MarkDownloaded(guid string) {
+	if !s.IsDownloaded(guid) {
+		s.Downloaded = append(s.Downloaded, guid)
+	}
+}
+
+// IsPlayed reports whether a podcast episode has been played.
+func (s *State) IsPlayed(guid string) bool {
+	return slices.Contains(s.Played, guid)
+}
+
+// MarkPlayed records that a podcast episode has been played. Played
+// episodes are left out of the download queue.
+func (s *State) MarkPlayed(guid string) {
+	if !s.IsPlayed(guid) {
+		s.Played = append(s.Played, guid)
+	}
+}
+
 // writeJSON marshals a value to JSON and writes it to path atomically.
 func writeJSON(path string, v any) error {
 	// WHY: Write to a temp file then rename — prevents partial reads if
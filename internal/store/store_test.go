@@ -85,6 +85,29 @@ func TestState_MarkUnread(t *testing.T) {
 	}
 }
 
+func TestState_PodcastEpisodes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := &State{Version: 1, Read: []string{}}
+	s.MarkDownloaded("ep-1")
+	s.MarkDownloaded("ep-1")
+	s.MarkPlayed("ep-2")
+
+	if err := SaveState(path, s); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+
+	if !loaded.IsDownloaded("ep-1") || len(loaded.Downloaded) != 1 {
+		t.Errorf("downloaded = %v, want [ep-1]", loaded.Downloaded)
+	}
+	if !loaded.IsPlayed("ep-2") || loaded.IsPlayed("ep-1") {
+		t.Errorf("played = %v, want [ep-2]", loaded.Played)
+	}
+}
+
 // --- Cache tests ---
 
 func TestLoadCache_FileNotExist(t *testing.T) {
@@ -92,8 +115,8 @@ func TestLoadCache_FileNotExist(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if c.Version != 1 {
-		t.Errorf("version = %d, want 1", c.Version)
+	if c.Version != cacheVersion {
+		t.Errorf("version = %d, want %d", c.Version, cacheVersion)
 	}
 	if c.ArticleCount() != 0 {
 		t.Errorf("article count = %d, want 0", c.ArticleCount())
@@ -104,12 +127,12 @@ func TestCache_RoundTrip(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "cache.json")
 
-	cache := newCache()
+	cache := NewCache()
 	now := time.Now()
-	cache.SetArticles("https://example.com/feed.xml", []model.Article{
+	cache.SetArticles("example", []model.Article{
 		{
 			GUID:        "article-1",
-			FeedURL:     "https://example.com/feed.xml",
+			FeedID:      "example",
 			Title:       "Test Article",
 			URL:         "https://example.com/post/1",
 			PublishedAt: now,
@@ -130,7 +153,7 @@ func TestCache_RoundTrip(t *testing.T) {
 		t.Fatalf("article count = %d, want 1", loaded.ArticleCount())
 	}
 
-	articles := loaded.ArticlesForFeed("https://example.com/feed.xml")
+	articles := loaded.ArticlesForFeed("example")
 	if len(articles) != 1 {
 		t.Fatalf("feed articles = %d, want 1", len(articles))
 	}
@@ -140,7 +163,7 @@ func TestCache_RoundTrip(t *testing.T) {
 }
 
 func TestCache_ArticlesForFeed_Empty(t *testing.T) {
-	c := newCache()
+	c := NewCache()
 	articles := c.ArticlesForFeed("https://nonexistent.com/feed")
 	if len(articles) != 0 {
 		t.Errorf("expected empty slice, got %d articles", len(articles))
@@ -148,7 +171,7 @@ func TestCache_ArticlesForFeed_Empty(t *testing.T) {
 }
 
 func TestCache_AllArticles(t *testing.T) {
-	c := newCache()
+	c := NewCache()
 	now := time.Now()
 
 	c.SetArticles("feed-1", []model.Article{
@@ -165,6 +188,61 @@ func TestCache_AllArticles(t *testing.T) {
 	}
 }
 
+func TestLoadCache_MigratesURLKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.json")
+
+	legacy := `{
+  "version": 1,
+  "articles": {
+    "https://example.com/feed.xml": [
+      {"guid": "a1", "feed_url": "https://example.com/feed.xml", "title": "Pinned"}
+    ],
+    "https://old.example.com/rss": [
+      {"guid": "a2", "feed_url": "https://old.example.com/rss", "title": "Orphan"}
+    ]
+  },
+  "last_fetched": {
+    "https://example.com/feed.xml": "2025-01-09T12:00:00Z"
+  }
+}`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadCache(path)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+
+	feeds := []model.Feed{{ID: "pinned", Name: "Pinned", URL: "https://example.com/feed.xml"}}
+	if !c.MigrateFeedKeys(feeds) {
+		t.Fatal("version 1 cache should be migrated")
+	}
+	if c.Version != cacheVersion {
+		t.Errorf("version = %d, want %d", c.Version, cacheVersion)
+	}
+
+	pinned := c.ArticlesForFeed("pinned")
+	if len(pinned) != 1 || pinned[0].FeedID != "pinned" {
+		t.Errorf("pinned articles = %+v, want one article with FeedID pinned", pinned)
+	}
+	if c.LastFetched["pinned"] != "2025-01-09T12:00:00Z" {
+		t.Errorf("last_fetched not re-keyed: %v", c.LastFetched)
+	}
+
+	// Keys with no configured feed fall back to the URL-derived ID.
+	orphanID := model.FeedIDFromURL("https://old.example.com/rss")
+	if len(c.ArticlesForFeed(orphanID)) != 1 {
+		t.Errorf("orphan articles should be keyed by %q", orphanID)
+	}
+
+	// A second migration is a no-op.
+	if c.MigrateFeedKeys(feeds) {
+		t.Error("current-version cache should not be migrated again")
+	}
+}
+
 // --- Bookmark tests ---
 
 func TestAppendBookmark(t *testing.T) {
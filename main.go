@@ -5,8 +5,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/mayknxyz/my-feeder/internal/config"
@@ -16,6 +19,26 @@ import (
 
 func main() {
 	// TODO: Replace with cobra CLI arg parsing in Phase 6.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			if err := runImport(os.Args[2:]); err != nil {
+				log.Fatal("Import failed", "error", err)
+			}
+			return
+		case "feeds":
+			if err := runFeeds(os.Args[2:]); err != nil {
+				log.Fatal("Feeds command failed", "error", err)
+			}
+			return
+		case "podcasts":
+			if err := runPodcasts(os.Args[2:]); err != nil {
+				log.Fatal("Podcasts command failed", "error", err)
+			}
+			return
+		}
+	}
+
 	configPath := ""
 	if len(os.Args) > 1 {
 		configPath = os.Args[1]
@@ -33,6 +56,9 @@ func main() {
 	if err != nil {
 		log.Fatal("Failed to load cache", "error", err)
 	}
+	if cache.MigrateFeedKeys(cfg.Feeds) {
+		log.Info("Migrated cache to feed IDs", "articles", cache.ArticleCount())
+	}
 
 	state, err := store.LoadState(cfg.Settings.StateFile)
 	if err != nil {
@@ -40,11 +66,9 @@ func main() {
 	}
 
 	// Fetch all feeds concurrently.
-	fetcher := &feed.Fetcher{
-		Feeds:       cfg.Feeds,
-		Cache:       cache,
-		GitHubToken: cfg.Settings.GitHubToken,
-		RetentionFn: cfg.RetentionDays,
+	fetcher, err := newFetcher(cfg, cache)
+	if err != nil {
+		log.Fatal("Failed to configure GitHub", "error", err)
 	}
 
 	fmt.Println("Fetching feeds...")
@@ -69,29 +93,79 @@ func main() {
 
 	for _, r := range results {
 		status := "ok"
-		if r.Err != nil {
+		if errors.Is(r.Err, feed.ErrDeferred) {
+			status = r.Err.Error()
+		} else if r.Err != nil {
 			status = fmt.Sprintf("error: %v", r.Err)
 		}
 
-		feedType := "rss"
-		if r.Feed.IsGitHub() {
-			feedType = "github"
-		}
+		feedType := r.Feed.Source()
 		tag := r.Feed.Tag
 		if tag == "" {
 			tag = "-"
 		}
 
-		count := len(cache.ArticlesForFeed(r.Feed.URL))
+		count := len(cache.ArticlesForFeed(r.Feed.ID))
 		newCount := len(r.Articles)
 
 		fmt.Printf("  [%s] [%s] %-25s %3d articles (%d new)  %s\n",
 			tag, feedType, r.Feed.Name, count, newCount, status)
 	}
 
+	if rate := fetcher.GitHub.RateLimit(); rate.Limit > 0 {
+		fmt.Println()
+		fmt.Printf("GitHub rate limit: %d/%d remaining, resets %s\n",
+			rate.Remaining, rate.Limit, rate.Reset.Local().Format(time.Kitchen))
+	}
+
 	fmt.Println()
 	fmt.Printf("Config:    %s\n", config.DefaultConfigPath())
 	fmt.Printf("Cache:     %s\n", cfg.Settings.CacheFile)
 	fmt.Printf("State:     %s\n", cfg.Settings.StateFile)
 	fmt.Printf("Bookmarks: %s\n", cfg.Settings.BookmarkFile)
 }
+
+// newFetcher wires a Fetcher for the configured feeds, with every source
+// set up from the config's settings.
+func newFetcher(cfg *config.Config, cache *store.Cache) (*feed.Fetcher, error) {
+	gh := feed.NewGitHub(cfg.Settings.GitHubToken)
+	if cfg.Settings.GitHubBaseURL != "" {
+		var err error
+		gh, err = feed.NewEnterpriseGitHub(cfg.Settings.GitHubToken, cfg.Settings.GitHubBaseURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	gh.GraphQL = cfg.Settings.GitHubGraphQL
+	return &feed.Fetcher{
+		Feeds:  cfg.Feeds,
+		Cache:  cache,
+		GitHub: gh,
+		GitLab: &feed.GitLab{
+			BaseURL: cfg.Settings.GitLabBaseURL,
+			Token:   cfg.Settings.GitLabToken,
+		},
+		Gitea:      &feed.Gitea{Tokens: cfg.Settings.GiteaTokens},
+		GoProxy:    &feed.GoProxy{URL: cfg.Settings.GoProxy},
+		OCI:        &feed.OCI{},
+		OSV:        &feed.OSV{BaseURL: cfg.Settings.OSVURL},
+		Mastodon:   &feed.Mastodon{Tokens: cfg.Settings.MastodonTokens},
+		HackerNews: &feed.HackerNews{},
+		Lobsters:   &feed.Lobsters{},
+		Scraper:    &feed.Scraper{},
+		JSONAPI:    &feed.JSONAPI{},
+		Calendar:   &feed.Calendar{},
+		Gemini:     &feed.Gemini{KnownHostsFile: config.GeminiKnownHostsPath()},
+		Watcher: &feed.Watcher{
+			// WHY: Snapshots sit beside the cache — like it, they can be
+			// rebuilt by fetching again, at the cost of one missed diff.
+			SnapshotDir: filepath.Join(filepath.Dir(cfg.Settings.CacheFile), "snapshots"),
+		},
+		Registries: &feed.Registries{
+			NPMURL:    cfg.Settings.NPMRegistry,
+			PyPIURL:   cfg.Settings.PyPIURL,
+			CratesURL: cfg.Settings.CratesURL,
+		},
+		RetentionFn: cfg.RetentionDays,
+	}, nil
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/mayknxyz/my-feeder/internal/config"
+	"github.com/mayknxyz/my-feeder/internal/podcast"
+	"github.com/mayknxyz/my-feeder/internal/store"
+)
+
+const podcastsUsage = "usage: feeder podcasts <list|download|played> [-config path] ..."
+
+// runPodcasts handles `feeder podcasts <command> ...`. Episodes come from
+// the cache, so they're whatever the last refresh found.
+func runPodcasts(args []string) error {
+	if len(args) == 0 {
+		return errors.New(podcastsUsage)
+	}
+	switch args[0] {
+	case "list":
+		return runPodcastsList(args[1:])
+	case "download":
+		return runPodcastsDownload(args[1:])
+	case "played":
+		return runPodcastsPlayed(args[1:])
+	}
+	return errors.New(podcastsUsage)
+}
+
+// podcastEnv is what every podcasts command loads.
+type podcastEnv struct {
+	cfg      *config.Config
+	state    *store.State
+	episodes []podcast.Episode
+}
+
+// loadPodcasts loads the config, read state and cached episodes.
+func loadPodcasts(configPath string) (*podcastEnv, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := store.LoadCache(cfg.Settings.CacheFile)
+	if err != nil {
+		return nil, err
+	}
+	cache.MigrateFeedKeys(cfg.Feeds)
+	state, err := store.LoadState(cfg.Settings.StateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(cfg.Feeds))
+	for _, f := range cfg.Feeds {
+		names[f.ID] = f.Name
+	}
+	return &podcastEnv{
+		cfg:      cfg,
+		state:    state,
+		episodes: podcast.Episodes(cache.AllArticles(), names),
+	}, nil
+}
+
+// runPodcastsList prints cached episodes, newest first, marked D when
+// downloaded and P when played.
+func runPodcastsList(args []string) error {
+	fs := flag.NewFlagSet("podcasts list", flag.ContinueOnError)
+	configPath := fs.String("config", "", "config file (default "+config.DefaultConfigPath()+")")
+	all := fs.Bool("all", false, "include played episodes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	env, err := loadPodcasts(*configPath)
+	if err != nil {
+		return err
+	}
+	shown := 0
+	for _, ep := range env.episodes {
+		played := env.state.IsPlayed(ep.Article.GUID)
+		if played && !*all {
+			continue
+		}
+		marks := []byte("  ")
+		if env.state.IsDownloaded(ep.Article.GUID) {
+			marks[0] = 'D'
+		}
+		if played {
+			marks[1] = 'P'
+		}
+		fmt.Printf("%s %s  %-20.20s %s%s\n", marks, ep.Article.PublishedAt.Local().Format("2006-01-02"),
+			ep.Feed, ep.Article.Title, episodeLength(ep))
+		shown++
+	}
+	if shown == 0 {
+		fmt.Println("No episodes cached. Podcast feeds show up here after a refresh.")
+	}
+	return nil
+}
+
+// episodeLength formats an episode's duration, or its size when the feed
+// gives no duration, for the end of a listing line.
+func episodeLength(ep podcast.Episode) string {
+	switch e := ep.Enclosure; {
+	case e.Duration > 0:
+		d := e.Duration.Round(time.Minute)
+		if d >= time.Hour {
+			return fmt.Sprintf(" (%dh%02dm)", int(d.Hours()), int(d.Minutes())%60)
+		}
+		return fmt.Sprintf(" (%dm)", int(d.Minutes()))
+	case e.Length > 0:
+		return fmt.Sprintf(" (%.1f MB)", float64(e.Length)/(1<<20))
+	}
+	return ""
+}
+
+// runPodcastsDownload downloads the newest episodes that haven't been
+// played and aren't in the podcast directory yet. Interrupting it with
+// Ctrl-C keeps partial files, and the next run resumes them.
+func runPodcastsDownload(args []string) error {
+	fs := flag.NewFlagSet("podcasts download", flag.ContinueOnError)
+	configPath := fs.String("config", "", "config file (default "+config.DefaultConfigPath()+")")
+	limit := fs.Int("n", 10, "most episodes to download (0 for all)")
+	jobs := fs.Int("j", 0, "downloads at once (default podcast_concurrency)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	env, err := loadPodcasts(*configPath)
+	if err != nil {
+		return err
+	}
+	dir := env.cfg.Settings.PodcastDir
+
+	var queue []podcast.Episode
+	for _, ep := range env.episodes {
+		if *limit > 0 && len(queue) == *limit {
+			break
+		}
+		if env.state.IsPlayed(ep.Article.GUID) {
+			continue
+		}
+		// WHY: Check the disk rather than the downloaded list — state is
+		// synced between machines, but the files aren't.
+		if _, err := os.Stat(podcast.Path(dir, ep)); err == nil {
+			continue
+		}
+		queue = append(queue, ep)
+	}
+	if len(queue) == 0 {
+		fmt.Println("Nothing to download.")
+		return nil
+	}
+
+	concurrency := env.cfg.Settings.PodcastConcurrency
+	if *jobs > 0 {
+		concurrency = *jobs
+	}
+	fmt.Printf("Downloading %d episodes to %s (%d at a time)...\n", len(queue), dir, concurrency)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	failed := 0
+	d := &podcast.Downloader{
+		Dir:         dir,
+		Concurrency: concurrency,
+		Done: func(r podcast.Result) {
+			if r.Err != nil {
+				failed++
+				fmt.Printf("  failed  %s: %v\n", r.Episode.Article.Title, r.Err)
+				return
+			}
+			env.state.MarkDownloaded(r.Episode.Article.GUID)
+			status := "done   "
+			if r.Resumed {
+				status = "resumed"
+			}
+			fmt.Printf("  %s %s\n", status, r.Path)
+		},
+	}
+	cancelled := 0
+	for _, r := range d.Download(ctx, queue) {
+		if r.Cancelled {
+			cancelled++
+		}
+	}
+	if cancelled > 0 {
+		fmt.Printf("Interrupted; %d episodes not started.\n", cancelled)
+	}
+
+	if err := store.SaveState(env.cfg.Settings.StateFile, env.state); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed; run again to resume them", failed, len(queue)-cancelled)
+	}
+	return nil
+}
+
+// runPodcastsPlayed marks the episode whose title matches as played,
+// which takes it out of the download queue.
+func runPodcastsPlayed(args []string) error {
+	fs := flag.NewFlagSet("podcasts played", flag.ContinueOnError)
+	configPath := fs.String("config", "", "config file (default "+config.DefaultConfigPath()+")")
+	remove := fs.Bool("delete", false, "also delete the downloaded file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: feeder podcasts played [-config path] [-delete] <title>")
+	}
+
+	env, err := loadPodcasts(*configPath)
+	if err != nil {
+		return err
+	}
+	ep, err := findEpisode(env.episodes, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	env.state.MarkPlayed(ep.Article.GUID)
+	if err := store.SaveState(env.cfg.Settings.StateFile, env.state); err != nil {
+		return err
+	}
+	fmt.Printf("Marked played: %s\n", ep.Article.Title)
+
+	if *remove {
+		path := podcast.Path(env.cfg.Settings.PodcastDir, ep)
+		switch err := os.Remove(path); {
+		case err == nil:
+			fmt.Printf("Deleted %s\n", path)
+		case !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+	}
+	return nil
+}
+
+// findEpisode looks an episode up by title: an exact match ignoring
+// case, or else the only title containing key.
+func findEpisode(eps []podcast.Episode, key string) (podcast.Episode, error) {
+	var matches []podcast.Episode
+	for _, ep := range eps {
+		if strings.EqualFold(ep.Article.Title, key) {
+			return ep, nil
+		}
+		if strings.Contains(strings.ToLower(ep.Article.Title), strings.ToLower(key)) {
+			matches = append(matches, ep)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return podcast.Episode{}, fmt.Errorf("no episode matches %q", key)
+	case 1:
+		return matches[0], nil
+	}
+	titles := make([]string, 0, len(matches))
+	for _, ep := range matches {
+		titles = append(titles, "\n  "+ep.Article.Title)
+	}
+	return podcast.Episode{}, fmt.Errorf("%q matches %d episodes:%s", key, len(matches), strings.Join(titles, ""))
+}